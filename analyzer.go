@@ -0,0 +1,177 @@
+package nargs
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Analyzer reports unused function parameters. It can be used with any
+// golang.org/x/tools/go/analysis driver (multichecker, gopls, nogo, ...).
+// Its flags mirror the fields of Flags. Configuration files are not read.
+var Analyzer = &analysis.Analyzer{
+	Name: "nargs",
+	Doc:  "reports unused function parameters",
+	Run:  run,
+}
+
+// analyzerFlags holds the configuration set through Analyzer.Flags.
+var analyzerFlags = Flags{
+	IncludeTests: true,
+}
+
+func init() {
+	Analyzer.Flags.BoolVar(&analyzerFlags.IncludeNamedReturns, "named_returns", analyzerFlags.IncludeNamedReturns, "Report unused named return arguments")
+	Analyzer.Flags.BoolVar(&analyzerFlags.IncludeReceivers, "receivers", analyzerFlags.IncludeReceivers, "Report unused function receivers")
+	Analyzer.Flags.BoolVar(&analyzerFlags.ReceiversByType, "receivers_by_type", analyzerFlags.ReceiversByType, "Report unused receivers per type, suggesting types whose methods could be functions")
+	Analyzer.Flags.BoolVar(&analyzerFlags.IncludeTypeParams, "type_params", analyzerFlags.IncludeTypeParams, "Report unused type parameters of generic functions")
+	Analyzer.Flags.BoolVar(&analyzerFlags.IncludeReceiverTypeParams, "receiver_type_params", analyzerFlags.IncludeReceiverTypeParams, "Report unused type parameters of generic receivers")
+	Analyzer.Flags.BoolVar(&analyzerFlags.WriteOnly, "write_only", analyzerFlags.WriteOnly, "Report parameters that are assigned to but never read")
+	Analyzer.Flags.BoolVar(&analyzerFlags.Overwritten, "overwritten", analyzerFlags.Overwritten, "Report parameters whose value is overwritten before it is read")
+	Analyzer.Flags.BoolVar(&analyzerFlags.Recursive, "recursive", analyzerFlags.Recursive, "Report parameters only passed unchanged to recursive calls")
+	Analyzer.Flags.BoolVar(&analyzerFlags.MutualRecursion, "mutual_recursion", analyzerFlags.MutualRecursion, "Like -recursive, also following calls to other functions of the same package")
+	Analyzer.Flags.BoolVar(&analyzerFlags.DeadCode, "dead_code", analyzerFlags.DeadCode, "Do not count uses of parameters in unreachable code")
+	Analyzer.Flags.StringVar(&analyzerFlags.BlankAssign, "blank_assign", analyzerFlags.BlankAssign, "Treatment of _ = param: use, suppress or report")
+	Analyzer.Flags.BoolVar(&analyzerFlags.StaleDirectives, "stale_directives", analyzerFlags.StaleDirectives, "Report nargs directives that suppress no finding")
+	Analyzer.Flags.BoolVar(&analyzerFlags.IncludeTests, "tests", analyzerFlags.IncludeTests, "include test (*_test.go) files")
+	Analyzer.Flags.BoolVar(&analyzerFlags.Typed, "typed", analyzerFlags.Typed, "Use type information to resolve identifiers")
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	flags := analyzerFlags
+	flags.ShowSuppressed = false
+	if err := flags.validate(); err != nil {
+		return nil, err
+	}
+
+	var facts packageFacts
+	for _, f := range pass.Files {
+		if !analyzerFlags.IncludeTests && isTestFile(pass.Fset.File(f.Pos()).Name()) {
+			continue
+		}
+
+		v := newUnusedVisitor(pass.Fset, flags)
+		if analyzerFlags.Typed {
+			v.info = pass.TypesInfo
+			v.declIdents = declIdents(pass.TypesInfo, f)
+		} else {
+			v.defs, v.uses = resolveFile(f)
+		}
+		v.suppressions, v.directives = fileDirectives(pass.Fset, f)
+		ast.Walk(v, f)
+		for _, result := range v.sortedResults() {
+			report(pass, result)
+		}
+		if flags.StaleDirectives {
+			for _, c := range v.directives {
+				if !v.matchedDirectives[c.Pos()] {
+					reportStale(pass, c)
+				}
+			}
+		}
+		facts.add(packageFacts{passThroughs: v.passThroughs, methods: v.methods})
+	}
+
+	// parameters only passed on to other functions of the package
+	results := unusedPassThroughs(facts.passThroughs)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ident.Pos() < results[j].ident.Pos()
+	})
+	for _, result := range results {
+		if result.suppressedBy == "" {
+			report(pass, result)
+		}
+	}
+
+	for _, recvType := range unusedReceiverTypes(pass.Fset, facts.methods) {
+		reportReceiverType(pass, recvType)
+	}
+	return nil, nil
+}
+
+// reportStale reports the directive c, which suppresses no finding, as a
+// diagnostic with a fix removing it.
+func reportStale(pass *analysis.Pass, c *ast.Comment) {
+	pass.Report(analysis.Diagnostic{
+		Pos:     c.Pos(),
+		End:     c.End(),
+		Message: fmt.Sprintf("directive %v suppresses no finding", directiveText(c)),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "Remove directive",
+			TextEdits: []analysis.TextEdit{{
+				Pos: c.Pos(),
+				End: c.End(),
+			}},
+		}},
+	})
+}
+
+// reportReceiverType reports recvType as a diagnostic on the receiver of its
+// first method not using it, relating the others.
+func reportReceiverType(pass *analysis.Pass, recvType receiverType) {
+	var related []analysis.RelatedInformation
+	for _, m := range recvType.unused[1:] {
+		related = append(related, analysis.RelatedInformation{
+			Pos:     m.typePos,
+			End:     m.typeEnd,
+			Message: fmt.Sprintf("%v does not use its receiver", m.name),
+		})
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:     recvType.unused[0].typePos,
+		End:     recvType.unused[0].typeEnd,
+		Message: recvType.finding(pass.Fset).Message(),
+		Related: related,
+	})
+}
+
+// report reports result as a diagnostic, with a fix renaming the parameter
+// to _ if it is not referenced in the body of its function, or only by
+// references to a variable shadowing it.
+func report(pass *analysis.Pass, result unusedParam) {
+	var related []analysis.RelatedInformation
+	switch result.reason {
+	case ReasonShadowed:
+		related = append(related, analysis.RelatedInformation{
+			Pos:     result.related,
+			Message: fmt.Sprintf("%v shadowed here", result.ident.Name),
+		})
+	case ReasonDeadCode:
+		related = append(related, analysis.RelatedInformation{
+			Pos:     result.related,
+			Message: fmt.Sprintf("%v used in dead code here", result.ident.Name),
+		})
+	case ReasonBlankAssign:
+		related = append(related, analysis.RelatedInformation{
+			Pos:     result.related,
+			Message: fmt.Sprintf("%v assigned to _ here", result.ident.Name),
+		})
+	case ReasonShadowedResult:
+		related = append(related, analysis.RelatedInformation{
+			Pos:     result.related,
+			Message: fmt.Sprintf("%v shadowed here", result.ident.Name),
+		})
+	}
+	diagnostic := analysis.Diagnostic{
+		Pos:     result.ident.Pos(),
+		End:     result.ident.End(),
+		Message: result.finding(pass.Fset).Message(),
+		Related: related,
+	}
+	switch result.reason {
+	case ReasonUnused, ReasonShadowed, ReasonNeverAssigned:
+		// other parameters are still referenced, renaming them would not
+		// compile
+		diagnostic.SuggestedFixes = []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("Rename %v to _", result.ident.Name),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     result.ident.Pos(),
+				End:     result.ident.End(),
+				NewText: []byte("_"),
+			}},
+		}}
+	}
+	pass.Report(diagnostic)
+}
@@ -0,0 +1,38 @@
+package nargs
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestAnalyzerReasons(t *testing.T) {
+	saved := analyzerFlags
+	t.Cleanup(func() { analyzerFlags = saved })
+	for name, value := range map[string]string{
+		"named_returns": "true",
+		"write_only":    "true",
+		"overwritten":   "true",
+		"recursive":     "true",
+		"dead_code":     "true",
+		"blank_assign":  BlankAssignReport,
+	} {
+		if err := Analyzer.Flags.Set(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, typed := range []string{"false", "true"} {
+		t.Run("typed="+typed, func(t *testing.T) {
+			if err := Analyzer.Flags.Set("typed", typed); err != nil {
+				t.Fatal(err)
+			}
+			// only unreferenced parameters get a fix renaming them to _
+			analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), Analyzer, "reasons")
+		})
+	}
+}
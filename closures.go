@@ -0,0 +1,142 @@
+package nargs
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+)
+
+// closureNames names every function literal in f after the function or
+// package-level variable enclosing it, the way the gc compiler does: the
+// literals in outerFunc are outerFunc.func1, outerFunc.func2, ... in source
+// order, and the literals nested in outerFunc.func1 are outerFunc.func1.1,
+// outerFunc.func1.2, and so on. Literals that are assigned to something are
+// named by nameAssignedClosures instead, but are still counted.
+func closureNames(f *ast.File) map[*ast.FuncLit]string {
+	names := make(map[*ast.FuncLit]string)
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Body != nil {
+				nameClosures(names, d.Name.Name+".func", d.Body)
+			}
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok || len(valueSpec.Names) == 0 {
+					continue
+				}
+				for index, value := range valueSpec.Values {
+					name := valueSpec.Names[0]
+					if index < len(valueSpec.Names) {
+						name = valueSpec.Names[index]
+					}
+					nameClosures(names, name.Name+".func", value)
+				}
+			}
+		}
+	}
+	nameAssignedClosures(names, f)
+	return names
+}
+
+// nameAssignedClosures names the function literals assigned to a variable,
+// struct field, map entry, etc. after the assignment target, e.g.
+// s.onEvent or table[k]. Function literals stored in a composite literal
+// are named after the target the composite literal is assigned to and their
+// key, e.g. handlers["someFunc"] for a map, cfg.OnClose for a struct or
+// filters[1] for a slice.
+func nameAssignedClosures(names map[*ast.FuncLit]string, f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+			for index, value := range node.Rhs {
+				target := types.ExprString(node.Lhs[index])
+				if funcLit, ok := value.(*ast.FuncLit); ok {
+					names[funcLit] = target
+				} else if lit := compositeLit(value); lit != nil {
+					nameCompositeElts(names, target, lit, nil)
+				}
+			}
+
+		case *ast.ValueSpec:
+			for index, value := range node.Values {
+				if lit := compositeLit(value); lit != nil && index < len(node.Names) {
+					nameCompositeElts(names, node.Names[index].Name, lit, nil)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// nameCompositeElts names the function literals among the elements of lit,
+// whose name is base. typ is the type of lit when it is elided, as for
+// composite literals nested in another.
+func nameCompositeElts(names map[*ast.FuncLit]string, base string, lit *ast.CompositeLit, typ ast.Expr) {
+	if lit.Type != nil {
+		typ = lit.Type
+	}
+	var eltType ast.Expr
+	indexed := false
+	switch t := typ.(type) {
+	case *ast.MapType:
+		eltType, indexed = t.Value, true
+	case *ast.ArrayType:
+		eltType, indexed = t.Elt, true
+	}
+	if star, ok := eltType.(*ast.StarExpr); ok {
+		eltType = star.X
+	}
+
+	for index, elt := range lit.Elts {
+		key := "[" + strconv.Itoa(index) + "]"
+		if keyValue, ok := elt.(*ast.KeyValueExpr); ok {
+			elt = keyValue.Value
+			if ident, ok := keyValue.Key.(*ast.Ident); ok && !indexed {
+				key = "." + ident.Name
+			} else {
+				key = "[" + types.ExprString(keyValue.Key) + "]"
+			}
+		}
+
+		if funcLit, ok := ast.Unparen(elt).(*ast.FuncLit); ok {
+			names[funcLit] = base + key
+		} else if nested := compositeLit(elt); nested != nil {
+			nameCompositeElts(names, base+key, nested, eltType)
+		}
+	}
+}
+
+// compositeLit returns the composite literal expr consists of, possibly
+// behind parentheses or an & operator, or nil if there is none.
+func compositeLit(expr ast.Expr) *ast.CompositeLit {
+	expr = ast.Unparen(expr)
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = ast.Unparen(unary.X)
+	}
+	lit, _ := expr.(*ast.CompositeLit)
+	return lit
+}
+
+// nameClosures names the outermost function literals in node prefix1,
+// prefix2, ... and recursively names the literals nested in them.
+func nameClosures(names map[*ast.FuncLit]string, prefix string, node ast.Node) {
+	count := 0
+	ast.Inspect(node, func(n ast.Node) bool {
+		funcLit, ok := n.(*ast.FuncLit)
+		if !ok {
+			return true
+		}
+		count++
+		name := prefix + strconv.Itoa(count)
+		names[funcLit] = name
+		nameClosures(names, name+".", funcLit.Body)
+		return false
+	})
+}
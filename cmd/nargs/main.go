@@ -24,21 +24,80 @@ func main() {
 	setExitStatus := flag.Bool("set_exit_status", true, "Set exit status to 1 if any issues are found")
 	includeNamedReturns := flag.Bool("named_returns", false, "Report unused named return arguments")
 	includeReceivers := flag.Bool("receivers", false, "Report unused function receivers")
+	receiversByType := flag.Bool("receivers_by_type", false, "Report unused receivers per type, suggesting types whose methods could be functions")
+	includeTypeParams := flag.Bool("type_params", false, "Report unused type parameters of generic functions")
+	includeReceiverTypeParams := flag.Bool("receiver_type_params", false, "Report unused type parameters of generic receivers")
+	writeOnly := flag.Bool("write_only", false, "Report parameters that are assigned to but never read")
+	overwritten := flag.Bool("overwritten", false, "Report parameters whose value is overwritten before it is read")
+	recursive := flag.Bool("recursive", false, "Report parameters only passed unchanged to recursive calls")
+	mutualRecursion := flag.Bool("mutual_recursion", false, "Like -recursive, also following calls to other functions of the same package")
+	deadCode := flag.Bool("dead_code", false, "Do not count uses of parameters in unreachable code")
+	blankAssign := flag.String("blank_assign", nargs.BlankAssignUse, "Treatment of _ = param: use (counts as a use), suppress (acknowledges the parameter is unused) or report")
+	showSuppressed := flag.Bool("show_suppressed", false, "Also list suppressed findings, marked as such")
+	staleDirectives := flag.Bool("stale_directives", false, "Report nargs directives that suppress no finding, e.g. as the parameter is now used")
+	typed := flag.Bool("typed", false, "Load packages with full type information to resolve identifiers")
+	mod := flag.String("mod", "", "Module download mode to use when loading packages: readonly, vendor or mod")
+	jobs := flag.Int("j", 0, "Number of files to parse and analyse in parallel, GOMAXPROCS if 0")
+	useConfig := flag.Bool("config", true, "Read .nargs.yaml configuration files, whose settings apply unless set by flags")
+	printConfig := flag.Bool("print_config", false, "Print the settings in effect in the current directory as a configuration file and exit")
 
 	flag.Parse()
 
 	flags := nargs.Flags{
-		IncludeTests:        *includeTests,
-		SetExitStatus:       *setExitStatus,
-		IncludeNamedReturns: *includeNamedReturns,
-		IncludeReceivers:    *includeReceivers,
+		IncludeTests:              *includeTests,
+		SetExitStatus:             *setExitStatus,
+		IncludeNamedReturns:       *includeNamedReturns,
+		IncludeReceivers:          *includeReceivers,
+		ReceiversByType:           *receiversByType,
+		IncludeTypeParams:         *includeTypeParams,
+		IncludeReceiverTypeParams: *includeReceiverTypeParams,
+		WriteOnly:                 *writeOnly,
+		Overwritten:               *overwritten,
+		Recursive:                 *recursive,
+		MutualRecursion:           *mutualRecursion,
+		DeadCode:                  *deadCode,
+		BlankAssign:               *blankAssign,
+		ShowSuppressed:            *showSuppressed,
+		StaleDirectives:           *staleDirectives,
+		Typed:                     *typed,
+		Mod:                       *mod,
+		Jobs:                      *jobs,
 	}
 
 	flag.Usage = usage
 
-	results, exitWithCode, err := nargs.CheckForUnusedFunctionArgs(flag.Args(), flags)
+	// flags set on the command line take precedence over configuration files
+	var explicit []string
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "config", "print_config":
+		case "j":
+			explicit = append(explicit, "jobs")
+		default:
+			explicit = append(explicit, f.Name)
+		}
+	})
+	cfg := nargs.Config{
+		Args:        flag.Args(),
+		Flags:       flags,
+		ConfigFiles: *useConfig,
+		Explicit:    explicit,
+	}
+
+	if *printConfig {
+		effective, err := cfg.EffectiveFlags()
+		if err == nil {
+			err = nargs.WriteConfig(os.Stdout, effective)
+		}
+		if err != nil {
+			log.Fatalf("ERROR: failed to run %s, %v\n", os.Args[0], err)
+		}
+		return
+	}
+
+	results, exitWithCode, err := cfg.Check()
 	if err != nil {
-		log.Printf("ERROR: failed to run %s, %v\n", os.Args[0], err)
+		log.Fatalf("ERROR: failed to run %s, %v\n", os.Args[0], err)
 	}
 
 	for _, result := range results {
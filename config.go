@@ -0,0 +1,358 @@
+package nargs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames are the names of configuration files, in order of
+// preference when a directory contains more than one. JSON files are read as
+// YAML, of which JSON is a subset.
+var configFileNames = []string{".nargs.yaml", ".nargs.yml", ".nargs.json"}
+
+// ConfigFile is the contents of a .nargs.yaml (or .nargs.yml, .nargs.json)
+// configuration file. The settings of a file apply to the files of its
+// directory and of all directories beneath it; those of files nested more
+// deeply take precedence, as do those of the overrides matching a file over
+// the top-level settings of their configuration file. Only the settings
+// present in a file or override are applied, e.g.
+//
+//	receivers: false
+//	allow: [ctx, "unused*"]
+//	exclude: ["**/*_gen.go", testdata]
+//	overrides:
+//	  - paths: [internal]
+//	    receivers: true
+//	  - paths: [api]
+//	    severity: warning
+//
+// Patterns of Include, Exclude and Paths are slash-separated paths relative
+// to the directory of the configuration file, in which ** matches any number
+// of directories and the other elements are path.Match patterns. A pattern
+// matching a directory matches every file beneath it.
+type ConfigFile struct {
+	Flags `yaml:",inline"`
+	// Root stops the search for configuration files in the enclosing
+	// directories.
+	Root bool `yaml:"root,omitempty" json:"root,omitempty"`
+	// Include restricts the analysis to the files matching one of its
+	// patterns, if it is not empty.
+	Include []string `yaml:"include,omitempty" json:"include,omitempty"`
+	// Exclude lists patterns of files not to analyse.
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	// Overrides lists settings applying to some files only, in order.
+	Overrides []Override `yaml:"overrides,omitempty" json:"overrides,omitempty"`
+
+	dir       string    // absolute directory of the file
+	settings  yaml.Node // the document, applied as Flags
+	overrides []yaml.Node
+}
+
+// Override holds the settings applying to the files matching one of Paths.
+type Override struct {
+	Paths []string `yaml:"paths" json:"paths"`
+	Flags `yaml:",inline"`
+}
+
+// ReadConfigFile reads and validates the configuration file filename.
+func ReadConfigFile(filename string) (*ConfigFile, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	c, err := parseConfigFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+	c.dir = dir
+	return c, nil
+}
+
+func parseConfigFile(data []byte) (*ConfigFile, error) {
+	c := new(ConfigFile)
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	// The nodes are kept to tell the settings present from those left out.
+	var raw struct {
+		Overrides []yaml.Node `yaml:"overrides"`
+	}
+	if err := yaml.Unmarshal(data, &c.settings); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	c.overrides = raw.Overrides
+	return c, nil
+}
+
+func (c *ConfigFile) validate() error {
+	if err := c.Flags.validate(); err != nil {
+		return err
+	}
+	patterns := append(append([]string(nil), c.Include...), c.Exclude...)
+	for i, override := range c.Overrides {
+		if len(override.Paths) == 0 {
+			return fmt.Errorf("override %v has no paths", i+1)
+		}
+		if err := override.Flags.validate(); err != nil {
+			return fmt.Errorf("override %v: %v", i+1, err)
+		}
+		patterns = append(patterns, override.Paths...)
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q, %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Apply returns flags with the settings of c applying to filename, which
+// must be absolute, overriding those of flags.
+func (c *ConfigFile) Apply(flags Flags, filename string) (Flags, error) {
+	flags, err := applySettings(flags, &c.settings)
+	if err != nil {
+		return flags, err
+	}
+	rel, ok := c.rel(filename)
+	if !ok {
+		return flags, nil
+	}
+	for i, override := range c.Overrides {
+		if matchAny(override.Paths, rel) {
+			if flags, err = applySettings(flags, &c.overrides[i]); err != nil {
+				return flags, err
+			}
+		}
+	}
+	return flags, nil
+}
+
+// includes reports whether filename, which must be absolute, is to be
+// analysed according to the Include and Exclude patterns of c.
+func (c *ConfigFile) includes(filename string) bool {
+	rel, ok := c.rel(filename)
+	if !ok {
+		return true
+	}
+	return (len(c.Include) == 0 || matchAny(c.Include, rel)) && !matchAny(c.Exclude, rel)
+}
+
+// rel returns filename relative to the directory of c, slash-separated, and
+// whether it is beneath that directory.
+func (c *ConfigFile) rel(filename string) (string, bool) {
+	rel, err := filepath.Rel(c.dir, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// applySettings returns flags with the settings of the YAML node overriding
+// its own; settings missing from node are left as they are.
+func applySettings(flags Flags, node *yaml.Node) (Flags, error) {
+	if node.Kind == 0 {
+		// empty document
+		return flags, nil
+	}
+	err := node.Decode(&flags)
+	return flags, err
+}
+
+// WriteConfig writes flags to w in the format of a configuration file.
+func WriteConfig(w io.Writer, flags Flags) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(ConfigFile{Flags: flags}); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// matchAny reports whether one of patterns matches the slash-separated path
+// name or one of the directories leading to it.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		pattern = path.Clean(strings.TrimPrefix(pattern, "/"))
+		if pattern == "." || matchElems(strings.Split(pattern, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchElems reports whether the pattern elements match the leading elements
+// of name, ** matching any number of them.
+func matchElems(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchElems(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchElems(pattern[1:], name[1:])
+}
+
+// configLoader finds the configuration files applying to the files to
+// analyse and resolves the flags of each of them.
+type configLoader struct {
+	base     Flags
+	explicit map[string]bool          // settings of base taking precedence
+	chains   map[string][]*ConfigFile // by directory, outermost first
+}
+
+// newConfigLoader returns a loader applying the configuration files found
+// to base, except for the settings named in explicit, e.g. "receivers",
+// which keep their value in base.
+func newConfigLoader(base Flags, explicit []string) *configLoader {
+	l := &configLoader{
+		base:     base,
+		explicit: make(map[string]bool, len(explicit)),
+		chains:   make(map[string][]*ConfigFile),
+	}
+	for _, key := range explicit {
+		l.explicit[key] = true
+	}
+	return l
+}
+
+// chain returns the configuration files applying to the directory dir,
+// which must be absolute, outermost first.
+func (l *configLoader) chain(dir string) ([]*ConfigFile, error) {
+	if chain, ok := l.chains[dir]; ok {
+		return chain, nil
+	}
+	c, err := findConfigFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	var chain []*ConfigFile
+	if parent := filepath.Dir(dir); parent != dir && (c == nil || !c.Root) {
+		if chain, err = l.chain(parent); err != nil {
+			return nil, err
+		}
+	}
+	if c != nil {
+		chain = append(chain[:len(chain):len(chain)], c)
+	}
+	l.chains[dir] = chain
+	return chain, nil
+}
+
+// findConfigFile returns the configuration file of dir, or nil if it has
+// none.
+func findConfigFile(dir string) (*ConfigFile, error) {
+	for _, name := range configFileNames {
+		c, err := ReadConfigFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return c, err
+	}
+	return nil, nil
+}
+
+// dirFlags returns the flags applying to the directory dir as a whole,
+// i.e. without the overrides of its configuration files.
+func (l *configLoader) dirFlags(dir string) (Flags, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return Flags{}, err
+	}
+	chain, err := l.chain(dir)
+	if err != nil {
+		return Flags{}, err
+	}
+	flags := l.base
+	for _, c := range chain {
+		if flags, err = applySettings(flags, &c.settings); err != nil {
+			return flags, err
+		}
+	}
+	return l.withExplicit(flags), nil
+}
+
+// fileFlags returns the flags applying to filename and whether it is to be
+// analysed.
+func (l *configLoader) fileFlags(filename string) (_ Flags, included bool, _ error) {
+	filename = absPath(filename)
+	chain, err := l.chain(filepath.Dir(filename))
+	if err != nil {
+		return Flags{}, false, err
+	}
+	flags := l.base
+	included = true
+	for _, c := range chain {
+		included = included && c.includes(filename)
+		if flags, err = c.Apply(flags, filename); err != nil {
+			return flags, false, err
+		}
+	}
+	return l.withExplicit(flags), included, nil
+}
+
+// withExplicit returns flags with the explicit settings of the base flags.
+func (l *configLoader) withExplicit(flags Flags) Flags {
+	value, base := reflect.ValueOf(&flags).Elem(), reflect.ValueOf(l.base)
+	for i := 0; i < value.NumField(); i++ {
+		key, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("yaml"), ",")
+		if l.explicit[key] {
+			value.Field(i).Set(base.Field(i))
+		}
+	}
+	return flags
+}
+
+// configure drops the files excluded by their configuration files and sets
+// the flags of the others.
+func (l *configLoader) configure(files []*sourceFile) ([]*sourceFile, error) {
+	configured := files[:0]
+	for _, sf := range files {
+		flags, included, err := l.fileFlags(sf.filename)
+		if err != nil {
+			return nil, err
+		}
+		if !included {
+			continue
+		}
+		if err := flags.validate(); err != nil {
+			return nil, fmt.Errorf("%v: %v", sf.filename, err)
+		}
+		sf.flags = &flags
+		configured = append(configured, sf)
+	}
+	return configured, nil
+}
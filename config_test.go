@@ -0,0 +1,266 @@
+package nargs
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTree creates the files of tree, keyed by slash-separated path, under
+// a temporary directory and returns it.
+func writeTree(t *testing.T, tree map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, contents := range tree {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const configTestSrc = `package p
+
+type T struct{}
+
+func (t T) F(a int, ctx int) (err error) { return nil }
+`
+
+func TestConfigFiles(t *testing.T) {
+	tree := map[string]string{
+		".nargs.yaml": `root: true
+allow: [ctx]
+exclude: ["**/*_gen.go"]
+overrides:
+  - paths: [internal]
+    receivers: true
+  - paths: [api]
+    severity: warning
+`,
+		"api/a.go":                 configTestSrc,
+		"api/b_gen.go":             configTestSrc,
+		"internal/a.go":            configTestSrc,
+		"internal/sub/.nargs.json": `{"named_returns": true}`,
+		"internal/sub/a.go":        configTestSrc,
+		"kinds/.nargs.yml":         "kinds: [receiver]\n",
+		"kinds/a.go":               configTestSrc,
+		"excluded/.nargs.yaml":     "include: [keep.go]\n",
+		"excluded/keep.go":         configTestSrc,
+		"excluded/skip.go":         configTestSrc,
+	}
+
+	tests := []struct {
+		name     string
+		flags    Flags
+		explicit []string
+		want     []string
+	}{
+		{
+			name: "overrides",
+			want: []string{
+				"api/a.go:5 F contains unused parameter a (warning)",
+				"excluded/keep.go:5 F contains unused parameter a",
+				"internal/a.go:5 F contains unused receiver t",
+				"internal/a.go:5 F contains unused parameter a",
+				"internal/sub/a.go:5 F contains unused receiver t",
+				"internal/sub/a.go:5 F contains unused parameter a",
+				"internal/sub/a.go:5 F contains unused named return err",
+				"kinds/a.go:5 F contains unused receiver t",
+			},
+		},
+		{
+			name:     "explicit flags",
+			flags:    Flags{ShowSuppressed: true},
+			explicit: []string{"receivers", "show_suppressed"},
+			want: []string{
+				"api/a.go:5 F contains unused parameter a (warning)",
+				"api/a.go:5 F contains unused parameter ctx (suppressed by allow: ctx) (warning)",
+				"excluded/keep.go:5 F contains unused parameter a",
+				"excluded/keep.go:5 F contains unused parameter ctx (suppressed by allow: ctx)",
+				"internal/a.go:5 F contains unused parameter a",
+				"internal/a.go:5 F contains unused parameter ctx (suppressed by allow: ctx)",
+				"internal/sub/a.go:5 F contains unused parameter a",
+				"internal/sub/a.go:5 F contains unused parameter ctx (suppressed by allow: ctx)",
+				"internal/sub/a.go:5 F contains unused named return err",
+				"kinds/a.go:5 F contains unused receiver t",
+			},
+		},
+	}
+	dir := writeTree(t, tree)
+	var args []string
+	for name := range tree {
+		if strings.HasSuffix(name, ".go") {
+			args = append(args, filepath.Join(dir, filepath.FromSlash(name)))
+		}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Args:        args,
+				Flags:       tt.flags,
+				ConfigFiles: true,
+				Explicit:    tt.explicit,
+			}
+			results, _, err := cfg.Check()
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			var got []string
+			for _, result := range results {
+				rel := strings.TrimPrefix(strings.TrimSuffix(result, "\n"), dir+string(filepath.Separator))
+				got = append(got, filepath.ToSlash(rel))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check()\ngot  %q,\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSeverity(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		".nargs.yaml": "root: true\nseverity: warning\n",
+		"a.go":        configTestSrc,
+	})
+	cfg := Config{
+		Args:        []string{filepath.Join(dir, "a.go")},
+		Flags:       Flags{SetExitStatus: true},
+		ConfigFiles: true,
+	}
+	results, exitWithStatus, err := cfg.Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(results) == 0 || exitWithStatus {
+		t.Errorf("Check() = %q, %v, want warnings not setting the exit status", results, exitWithStatus)
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		want    Flags
+		wantErr string
+	}{
+		{
+			name: "yaml",
+			file: ".nargs.yaml",
+			data: "receivers: true\nkinds: [param, receiver]\nseverity: warning\n",
+			want: Flags{IncludeReceivers: true, Kinds: []string{"param", "receiver"}, Severity: SeverityWarning},
+		},
+		{
+			name: "json",
+			file: ".nargs.json",
+			data: `{"blank_assign": "report", "allow": ["ctx"], "jobs": 2}`,
+			want: Flags{BlankAssign: BlankAssignReport, Allow: []string{"ctx"}, Jobs: 2},
+		},
+		{
+			name: "empty",
+			file: ".nargs.yaml",
+		},
+		{
+			name:    "unknown setting",
+			file:    ".nargs.yaml",
+			data:    "receiver: true\n",
+			wantErr: "field receiver not found",
+		},
+		{
+			name:    "unknown override setting",
+			file:    ".nargs.yaml",
+			data:    "overrides:\n  - paths: [api]\n    severity: info\n",
+			wantErr: `override 1: invalid severity "info"`,
+		},
+		{
+			name:    "unknown kind",
+			file:    ".nargs.yaml",
+			data:    "kinds: [params]\n",
+			wantErr: `invalid finding kind "params"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTree(t, map[string]string{tt.file: tt.data})
+			c, err := ReadConfigFile(filepath.Join(dir, tt.file))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ReadConfigFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadConfigFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(c.Flags, tt.want) {
+				t.Errorf("ReadConfigFile() flags = %+v, want %+v", c.Flags, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteConfig(t *testing.T) {
+	flags := Flags{
+		IncludeTests:    true,
+		SetExitStatus:   true,
+		ReceiversByType: true,
+		BlankAssign:     BlankAssignSuppress,
+		Jobs:            4,
+		Allow:           []string{"ctx", "_*"},
+		Kinds:           []string{"param", "receiver-type"},
+		Severity:        SeverityWarning,
+	}
+	var buf bytes.Buffer
+	if err := WriteConfig(&buf, flags); err != nil {
+		t.Fatalf("WriteConfig() error = %v", err)
+	}
+	dir := writeTree(t, map[string]string{".nargs.yaml": buf.String()})
+	c, err := ReadConfigFile(filepath.Join(dir, ".nargs.yaml"))
+	if err != nil {
+		t.Fatalf("ReadConfigFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(c.Flags, flags) {
+		t.Errorf("ReadConfigFile(WriteConfig()) = %+v, want %+v", c.Flags, flags)
+	}
+	got, err := c.Apply(Flags{Typed: true}, filepath.Join(dir, "a.go"))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	// every setting is written, so none of those of the base flags remain
+	if !reflect.DeepEqual(got, flags) {
+		t.Errorf("Apply() = %+v, want %+v", got, flags)
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"internal", "internal/a.go", true},
+		{"internal", "internal/sub/a.go", true},
+		{"internal", "api/internal/a.go", false},
+		{"/internal/", "internal/a.go", true},
+		{"*.go", "a.go", true},
+		{"*.go", "sub/a.go", false},
+		{"**/*.go", "sub/a.go", true},
+		{"**/*_gen.go", "a_gen.go", true},
+		{"api/**/legacy", "api/v1/v2/legacy/a.go", true},
+		{"api/**/legacy", "api/legacy/a.go", true},
+		{"api/*/legacy", "api/legacy/a.go", false},
+		{".", "a.go", true},
+	}
+	for _, tt := range tests {
+		if got := matchAny([]string{tt.pattern}, tt.name); got != tt.want {
+			t.Errorf("matchAny(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
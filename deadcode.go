@@ -0,0 +1,151 @@
+package nargs
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+)
+
+// liveStmts returns the statements of stmtList that can be reached, handing
+// the others to handleDead: with -dead_code, the statements following a
+// return, a panic or a branch statement are unreachable up to the next
+// labeled statement, which may be the target of a goto.
+func (v *unusedVisitor) liveStmts(paramMap map[*ast.Ident]bool, stmtList []ast.Stmt) []ast.Stmt {
+	if !v.deadCode {
+		return stmtList
+	}
+
+	var live []ast.Stmt
+	for index := 0; index < len(stmtList); index++ {
+		live = append(live, stmtList[index])
+		if !terminates(stmtList[index]) {
+			continue
+		}
+
+		end := index + 1
+		for end < len(stmtList) {
+			if _, ok := stmtList[end].(*ast.LabeledStmt); ok {
+				break
+			}
+			end++
+		}
+		v.handleDead(paramMap, stmtList[index+1:end])
+		index = end - 1
+	}
+	return live
+}
+
+// liveBranches returns the branches of ifStmt that can be taken, handing
+// the other to handleDead: with -dead_code, the body of an if statement
+// whose condition is constant false is unreachable, and so is the else
+// branch when the condition is constant true.
+func (v *unusedVisitor) liveBranches(paramMap map[*ast.Ident]bool, ifStmt *ast.IfStmt) (body, els ast.Stmt) {
+	if !v.deadCode {
+		return ifStmt.Body, ifStmt.Else
+	}
+
+	cond, ok := v.constBool(ifStmt.Cond)
+	switch {
+	case !ok:
+		return ifStmt.Body, ifStmt.Else
+	case cond:
+		v.handleDead(paramMap, []ast.Stmt{ifStmt.Else})
+		return ifStmt.Body, nil
+	default:
+		v.handleDead(paramMap, []ast.Stmt{ifStmt.Body})
+		return nil, ifStmt.Else
+	}
+}
+
+// handleDead handles unreachable statements: the parameters in paramMap
+// they refer to are not marked as used, but the position of the first such
+// statement is recorded for each of them.
+func (v *unusedVisitor) handleDead(paramMap map[*ast.Ident]bool, stmtList []ast.Stmt) {
+	for _, stmt := range stmtList {
+		if stmt == nil {
+			continue
+		}
+		deadMap := make(map[*ast.Ident]bool, len(paramMap))
+		for param := range paramMap {
+			deadMap[param] = false
+		}
+		v.handleStmts(deadMap, []ast.Stmt{stmt})
+		for param, used := range deadMap {
+			if used && !v.deadUses[param].IsValid() {
+				v.deadUses[param] = stmt.Pos()
+			}
+		}
+	}
+}
+
+// terminates reports whether no statement directly following stmt in the
+// same block can be reached from stmt.
+func terminates(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return s.Tok != token.FALLTHROUGH
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		return ok && isPanic(call)
+	}
+	return false
+}
+
+// constBool returns the value of expr if it is a constant boolean, or can
+// be decided from its constant operands, e.g. debug && x with debug false.
+// Without type information, only the constants declared in the file being
+// analysed are known.
+func (v *unusedVisitor) constBool(expr ast.Expr) (value, ok bool) {
+	if v.info != nil {
+		if tv, ok := v.info.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.Bool {
+			return constant.BoolVal(tv.Value), true
+		}
+	}
+
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return v.constBool(e.X)
+
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			value, ok := v.constBool(e.X)
+			return !value, ok
+		}
+
+	case *ast.BinaryExpr:
+		if e.Op != token.LAND && e.Op != token.LOR {
+			break
+		}
+		// x && false is false and x || true is true, whatever x is
+		decisive := e.Op == token.LOR
+		x, xok := v.constBool(e.X)
+		y, yok := v.constBool(e.Y)
+		switch {
+		case (xok && x == decisive) || (yok && y == decisive):
+			return decisive, true
+		case xok && yok:
+			return !decisive, true
+		}
+
+	case *ast.Ident:
+		if v.info != nil {
+			break
+		}
+		obj := v.uses[e]
+		if obj == nil {
+			// unresolved: a predeclared identifier, or declared in another file
+			return e.Name == "true", e.Name == "true" || e.Name == "false"
+		}
+		if obj.kind != objConst || obj.spec == nil {
+			break
+		}
+		for index, name := range obj.spec.Names {
+			if name == obj.name && index < len(obj.spec.Values) {
+				return v.constBool(obj.spec.Values[index])
+			}
+		}
+	}
+	return false, false
+}
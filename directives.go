@@ -0,0 +1,290 @@
+package nargs
+
+import (
+	"go/ast"
+	"go/token"
+	"path"
+	"strings"
+	"time"
+)
+
+// suppression is a range of a file in which findings are suppressed by a
+// directive comment.
+type suppression struct {
+	pos, end  token.Pos
+	directive *ast.Comment
+}
+
+// Directive comments suppressing findings, until the date given by an
+// optional until=YYYY-MM-DD:
+//
+//	//nargs:ignore [reason]   on or above a function declaration or literal
+//	//nolint:nargs            likewise, or at the end of the line to silence
+//	/*nargs:ok*/              next to the name of a single parameter
+const (
+	ignoreDirective = "//nargs:ignore"
+	okDirective     = "/*nargs:ok"
+	nolintDirective = "//nolint"
+)
+
+// fileDirectives returns the ranges of f in which findings are suppressed
+// by directives, and the directives specific to nargs, which are stale if
+// they suppress nothing. A //nargs:ignore or //nolint:nargs directive on the line a
+// function starts on or the line above it, or in the doc comment of a
+// function declaration, covers the whole function, closures included; a
+// //nolint:nargs directive also covers the line it is on. A /*nargs:ok*/
+// directive covers the parameter name it follows, including one whose type
+// it follows on the same line, or otherwise the first name of the parameter
+// field it precedes.
+func fileDirectives(fset *token.FileSet, f *ast.File) (suppressions []suppression, directives []*ast.Comment) {
+	var oks []*ast.Comment
+	directivesByLine := make(map[int][]*ast.Comment)
+	file := fset.File(f.Pos())
+	for _, group := range f.Comments {
+		for _, c := range group.List {
+			nolint, explicit := isNolint(c.Text)
+			switch {
+			case isOK(c.Text):
+				oks = append(oks, c)
+			case isIgnore(c.Text):
+				line := file.Line(c.Pos())
+				directivesByLine[line] = append(directivesByLine[line], c)
+			case nolint:
+				line := file.Line(c.Pos())
+				directivesByLine[line] = append(directivesByLine[line], c)
+				suppressions = append(suppressions, suppression{pos: file.LineStart(line), end: lineEnd(file, line), directive: c})
+			default:
+				continue
+			}
+			if !nolint || explicit {
+				directives = append(directives, c)
+			}
+		}
+	}
+	if len(directivesByLine) == 0 && len(oks) == 0 {
+		return suppressions, directives
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		var doc *ast.CommentGroup
+		var fieldLists []*ast.FieldList
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			doc = n.Doc
+			fieldLists = []*ast.FieldList{n.Recv, n.Type.TypeParams, n.Type.Params, n.Type.Results}
+		case *ast.FuncLit:
+			fieldLists = []*ast.FieldList{n.Type.Params, n.Type.Results}
+		default:
+			return true
+		}
+
+		line := file.Line(n.Pos())
+		var funcDirectives []*ast.Comment
+		funcDirectives = append(funcDirectives, directivesByLine[line-1]...)
+		funcDirectives = append(funcDirectives, directivesByLine[line]...)
+		if doc != nil {
+			for _, c := range doc.List {
+				if nolint, _ := isNolint(c.Text); nolint || isIgnore(c.Text) {
+					funcDirectives = append(funcDirectives, c)
+				}
+			}
+		}
+		for _, c := range funcDirectives {
+			suppressions = append(suppressions, suppression{pos: n.Pos(), end: n.End(), directive: c})
+		}
+
+		for _, list := range fieldLists {
+			suppressions = append(suppressions, okSuppressions(file, list, oks)...)
+		}
+		return true
+	})
+	return suppressions, directives
+}
+
+// okSuppressions returns the parameters of list covered by the /*nargs:ok*/
+// comments oks.
+func okSuppressions(file *token.File, list *ast.FieldList, oks []*ast.Comment) []suppression {
+	if list == nil || len(oks) == 0 {
+		return nil
+	}
+	var suppressions []suppression
+	for _, c := range oks {
+		if c.Pos() < list.Pos() || c.Pos() >= list.End() {
+			continue
+		}
+		if name := okParam(file, list, c); name != nil {
+			suppressions = append(suppressions, suppression{pos: name.Pos(), end: name.End(), directive: c})
+		}
+	}
+	return suppressions
+}
+
+// okParam returns the parameter name of list covered by the /*nargs:ok*/
+// comment c: the name c follows within its field, the last name of the
+// field c follows on the same line, or else the first name of the field c
+// precedes.
+func okParam(file *token.File, list *ast.FieldList, c *ast.Comment) *ast.Ident {
+	var prev *ast.Ident // last name of a field c follows on the same line
+	for _, field := range list.List {
+		switch {
+		case c.Pos() < field.Pos():
+			if prev != nil {
+				return prev
+			}
+			if len(field.Names) == 0 {
+				return nil
+			}
+			return field.Names[0]
+
+		case c.Pos() < field.End():
+			var name *ast.Ident
+			for _, n := range field.Names {
+				if name == nil || n.Pos() < c.Pos() {
+					name = n
+				}
+			}
+			return name
+		}
+		prev = nil
+		if len(field.Names) != 0 && file.Line(field.End()) == file.Line(c.Pos()) {
+			prev = field.Names[len(field.Names)-1]
+		}
+	}
+	return prev
+}
+
+// suppress marks result as suppressed by the innermost directive covering
+// its parameter, or as no longer suppressed if that directive has expired.
+// Otherwise, parameters whose name matches a pattern of Flags.Allow are
+// suppressed by it.
+func (v *unusedVisitor) suppress(result *unusedParam) {
+	var innermost *suppression
+	for i, s := range v.suppressions {
+		if s.pos <= result.ident.Pos() && result.ident.Pos() < s.end && (innermost == nil || s.end-s.pos < innermost.end-innermost.pos) {
+			innermost = &v.suppressions[i]
+		}
+	}
+	if innermost != nil {
+		result.directive = innermost.directive.Pos()
+		if expired(innermost.directive.Text) {
+			result.expiredBy = directiveText(innermost.directive)
+		} else {
+			result.suppressedBy = directiveText(innermost.directive)
+		}
+	}
+	if result.suppressedBy != "" {
+		return
+	}
+	for _, pattern := range v.allow {
+		if ok, _ := path.Match(pattern, result.ident.Name); ok {
+			result.suppressedBy = "allow: " + pattern
+			return
+		}
+	}
+}
+
+// matched records that the directive covering result, if any, is not stale.
+func (v *unusedVisitor) matched(result unusedParam) {
+	if result.directive.IsValid() {
+		v.matchedDirectives[result.directive] = true
+	}
+}
+
+// staleDirectives returns the nargs directives of the current file that
+// cover no finding.
+func (v *unusedVisitor) staleDirectives() []Finding {
+	var findings []Finding
+	for _, c := range v.directives {
+		if v.matchedDirectives[c.Pos()] {
+			continue
+		}
+		findings = append(findings, Finding{
+			Pos:       v.fileSet.Position(c.Pos()),
+			End:       v.fileSet.Position(c.End()),
+			FuncPos:   v.fileSet.Position(c.Pos()),
+			Kind:      KindDirective,
+			Reason:    ReasonStale,
+			Directive: directiveText(c),
+			Severity:  v.severity,
+		})
+	}
+	return findings
+}
+
+// timeNow returns the current time, against which until= dates are checked.
+var timeNow = time.Now
+
+// expired reports whether the directive text carries an until=YYYY-MM-DD
+// date that has passed, or that is not a valid date. A directive suppresses
+// findings through the day of its date.
+func expired(text string) bool {
+	for _, field := range strings.Fields(strings.TrimSuffix(text, "*/")) {
+		value, ok := strings.CutPrefix(field, "until=")
+		if !ok {
+			continue
+		}
+		until, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		return err != nil || !timeNow().Before(until.AddDate(0, 0, 1))
+	}
+	return false
+}
+
+func isIgnore(text string) bool {
+	return hasDirective(text, ignoreDirective)
+}
+
+func isOK(text string) bool {
+	return hasDirective(text, okDirective) && strings.HasSuffix(text, "*/")
+}
+
+// isNolint reports whether text is a //nolint directive applying to nargs:
+// to all linters, or explicitly to a list of linters including nargs. The
+// form // nolint, which gofmt turns a bare //nolint doc comment into, is
+// accepted too.
+func isNolint(text string) (nolint, explicit bool) {
+	if rest, ok := strings.CutPrefix(text, "// nolint"); ok {
+		text = nolintDirective + rest
+	}
+	if !hasDirective(text, nolintDirective) && !strings.HasPrefix(text, nolintDirective+":") {
+		return false, false
+	}
+	rest := strings.TrimPrefix(text, nolintDirective)
+	if !strings.HasPrefix(rest, ":") {
+		return true, false
+	}
+	linters := strings.Fields(rest[1:])
+	if len(linters) == 0 {
+		return false, false
+	}
+	for _, linter := range strings.Split(linters[0], ",") {
+		if linter == "nargs" {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// hasDirective reports whether text is the directive comment, possibly
+// followed by a space and an explanation.
+func hasDirective(text, directive string) bool {
+	if !strings.HasPrefix(text, directive) {
+		return false
+	}
+	rest := text[len(directive):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t' || strings.HasPrefix(rest, "*/")
+}
+
+// directiveText returns the text of c as it should be shown for the
+// findings it suppresses.
+func directiveText(c *ast.Comment) string {
+	return strings.TrimSpace(c.Text)
+}
+
+// lineEnd returns the position just after the last character of line in
+// file.
+func lineEnd(file *token.File, line int) token.Pos {
+	if line < file.LineCount() {
+		return file.LineStart(line + 1)
+	}
+	return token.Pos(file.Base() + file.Size())
+}
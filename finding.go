@@ -0,0 +1,251 @@
+package nargs
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// Kind identifies the sort of parameter a Finding refers to.
+type Kind int
+
+const (
+	// KindParam is a parameter of a function or method declaration.
+	KindParam Kind = iota
+	// KindReceiver is the receiver of a method.
+	KindReceiver
+	// KindNamedReturn is a named result of a function or method declaration.
+	KindNamedReturn
+	// KindClosureParam is a parameter of a function literal.
+	KindClosureParam
+	// KindTypeParam is a type parameter of a generic function.
+	KindTypeParam
+	// KindReceiverTypeParam is a type parameter declared by the receiver of
+	// a method of a generic type.
+	KindReceiverTypeParam
+	// KindReceiverType is a type whose methods do not use their receivers,
+	// with -receivers_by_type. Recv and Methods are set, Func and Param are
+	// empty.
+	KindReceiverType
+	// KindDirective is a suppression directive, with -stale_directives.
+	// Directive is set, Func and Param are empty.
+	KindDirective
+)
+
+var kindNames = [...]string{
+	KindParam:             "param",
+	KindReceiver:          "receiver",
+	KindNamedReturn:       "named-return",
+	KindClosureParam:      "closure-param",
+	KindTypeParam:         "type-param",
+	KindReceiverTypeParam: "receiver-type-param",
+	KindReceiverType:      "receiver-type",
+	KindDirective:         "directive",
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+	return kindNames[k]
+}
+
+// parseKind returns the Kind named name, as returned by Kind.String.
+func parseKind(name string) (Kind, bool) {
+	for k, kindName := range kindNames {
+		if kindName == name {
+			return Kind(k), true
+		}
+	}
+	return 0, false
+}
+
+// Reason explains why a parameter is reported.
+type Reason int
+
+const (
+	// ReasonUnused is a parameter that is never referenced.
+	ReasonUnused Reason = iota
+	// ReasonWriteOnly is a parameter that is assigned to but never read.
+	ReasonWriteOnly
+	// ReasonOverwritten is a parameter whose value on entry to the function
+	// is overwritten before it is ever read.
+	ReasonOverwritten
+	// ReasonRecursive is a parameter only passed unchanged to recursive
+	// calls of its function, or of other functions of its package.
+	ReasonRecursive
+	// ReasonShadowed is a parameter that is never used because a variable
+	// of the same name is declared in the function body before any use.
+	ReasonShadowed
+	// ReasonDeadCode is a parameter only used in unreachable code.
+	ReasonDeadCode
+	// ReasonBlankAssign is a parameter only used by assigning it to the
+	// blank identifier, _ = param, instead of being named _.
+	ReasonBlankAssign
+	// ReasonNeverAssigned is a named result that is never assigned to, so
+	// it is always the zero value.
+	ReasonNeverAssigned
+	// ReasonNakedReturn is a named result that is assigned to but never
+	// read, other than by being returned by naked returns.
+	ReasonNakedReturn
+	// ReasonShadowedResult is a named result shadowed by a variable declared
+	// in a nested scope that is followed by a naked return, which returns
+	// the named result rather than the shadowing variable.
+	ReasonShadowedResult
+	// ReasonNoReceiverUse is a receiver type none of whose methods use
+	// their receiver, so that they could be plain functions.
+	ReasonNoReceiverUse
+	// ReasonStale is a directive that suppresses no finding.
+	ReasonStale
+)
+
+var reasonNames = [...]string{
+	ReasonUnused:         "unused",
+	ReasonWriteOnly:      "write-only",
+	ReasonOverwritten:    "overwritten",
+	ReasonRecursive:      "recursion-only",
+	ReasonShadowed:       "shadowed",
+	ReasonDeadCode:       "dead-code",
+	ReasonBlankAssign:    "blank-assigned",
+	ReasonNeverAssigned:  "never-assigned",
+	ReasonNakedReturn:    "naked-return-only",
+	ReasonShadowedResult: "shadowed-result",
+	ReasonNoReceiverUse:  "no-receiver-use",
+	ReasonStale:          "stale",
+}
+
+func (r Reason) String() string {
+	if r < 0 || int(r) >= len(reasonNames) {
+		return fmt.Sprintf("Reason(%d)", int(r))
+	}
+	return reasonNames[r]
+}
+
+// Finding describes a single unused parameter.
+type Finding struct {
+	// Pos and End delimit the name of the parameter.
+	Pos token.Position
+	End token.Position
+	// FuncPos is the position of the declaration or literal of the
+	// function the parameter belongs to.
+	FuncPos token.Position
+	// Func is the name of the function the parameter belongs to. Closures
+	// are named after the variable they are assigned to.
+	Func string
+	// Recv is the receiver type name of the method the parameter belongs
+	// to, or empty for functions and closures.
+	Recv string
+	// Param is the name of the parameter.
+	Param  string
+	Kind   Kind
+	Reason Reason
+	// Related is the position of the declaration shadowing the parameter
+	// for ReasonShadowed, of the first unreachable statement using it for
+	// ReasonDeadCode, of the first _ = param assignment for
+	// ReasonBlankAssign, of the shadowing declaration for
+	// ReasonShadowedResult, and the zero Position otherwise.
+	Related token.Position
+	// Methods lists the methods of Recv not using their receiver, for
+	// KindReceiverType, in the order they are declared.
+	Methods []string
+	// SuppressedBy describes what suppresses the finding, e.g. "_ = c", or
+	// is empty if the finding is not suppressed. Suppressed findings are
+	// only reported with Flags.ShowSuppressed.
+	SuppressedBy string
+	// ExpiredBy is the directive that suppressed the finding until its
+	// until= date passed, or is empty.
+	ExpiredBy string
+	// Directive is the text of the directive, for KindDirective.
+	Directive string
+	// Severity is the Flags.Severity the finding is reported with, empty
+	// standing for SeverityError.
+	Severity string
+}
+
+// Message describes f without its position, e.g.
+// "funcOne contains unused parameter c".
+func (f Finding) Message() string {
+	noun := "parameter"
+	switch f.Kind {
+	case KindReceiver:
+		noun = "receiver"
+	case KindTypeParam, KindReceiverTypeParam:
+		noun = "type parameter"
+	case KindNamedReturn:
+		noun = "named return"
+	case KindDirective:
+		return fmt.Sprintf("directive %v suppresses no finding", f.Directive)
+	case KindReceiverType:
+		methods := strings.Join(f.Methods, ", ")
+		if f.Reason == ReasonNoReceiverUse {
+			return fmt.Sprintf("%v uses its receiver in none of its methods (%v), convert them to functions", f.Recv, methods)
+		}
+		return fmt.Sprintf("%v contains unused receiver in methods %v", f.Recv, methods)
+	}
+	switch f.Reason {
+	case ReasonShadowed:
+		return fmt.Sprintf("%v contains %v %v shadowed before use on line %v", f.Func, noun, f.Param, f.Related.Line)
+	case ReasonDeadCode:
+		return fmt.Sprintf("%v contains %v %v only used in dead code on line %v", f.Func, noun, f.Param, f.Related.Line)
+	case ReasonNeverAssigned:
+		return fmt.Sprintf("%v contains %v %v that is never assigned", f.Func, noun, f.Param)
+	case ReasonNakedReturn:
+		return fmt.Sprintf("%v contains %v %v only returned by naked returns", f.Func, noun, f.Param)
+	case ReasonShadowedResult:
+		return fmt.Sprintf("%v contains %v %v shadowed on line %v before a naked return", f.Func, noun, f.Param, f.Related.Line)
+	case ReasonBlankAssign:
+		return fmt.Sprintf("%v contains %v %v used only in blank assignment on line %v, rename to _ instead", f.Func, noun, f.Param, f.Related.Line)
+	}
+	return fmt.Sprintf("%v contains %v %v %v", f.Func, f.Reason, noun, f.Param)
+}
+
+// String renders f in the format printed by the nargs command, e.g.
+// "test.go:6 funcOne contains unused parameter c", followed by what
+// suppresses it if it is suppressed, or by the expired directive that used to,
+// and by "(warning)" if it is a warning.
+func (f Finding) String() string {
+	s := fmt.Sprintf("%v:%v %v", f.FuncPos.Filename, f.FuncPos.Line, f.Message())
+	switch {
+	case f.SuppressedBy != "":
+		s += fmt.Sprintf(" (suppressed by %v)", f.SuppressedBy)
+	case f.ExpiredBy != "":
+		s += fmt.Sprintf(" (suppression by %v expired)", f.ExpiredBy)
+	}
+	if f.Warning() {
+		s += " (warning)"
+	}
+	return s
+}
+
+// Warning reports whether f is reported with SeverityWarning, so that it
+// does not set the exit status.
+func (f Finding) Warning() bool {
+	return f.Severity == SeverityWarning
+}
+
+// Less reports whether f sorts before g. Findings are ordered by file name,
+// then by the position of the parameter, then by kind, reason, function,
+// parameter name and related position, so that any two distinct findings
+// have a fixed order.
+func (f Finding) Less(g Finding) bool {
+	switch {
+	case f.Pos.Filename != g.Pos.Filename:
+		return f.Pos.Filename < g.Pos.Filename
+	case f.Pos.Line != g.Pos.Line:
+		return f.Pos.Line < g.Pos.Line
+	case f.Pos.Column != g.Pos.Column:
+		return f.Pos.Column < g.Pos.Column
+	case f.Kind != g.Kind:
+		return f.Kind < g.Kind
+	case f.Reason != g.Reason:
+		return f.Reason < g.Reason
+	case f.Func != g.Func:
+		return f.Func < g.Func
+	case f.Param != g.Param:
+		return f.Param < g.Param
+	case f.Related.Line != g.Related.Line:
+		return f.Related.Line < g.Related.Line
+	default:
+		return f.Related.Column < g.Related.Column
+	}
+}
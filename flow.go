@@ -0,0 +1,166 @@
+package nargs
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+// flowResult is the effect of a statement on the value a parameter holds on
+// entry to its function.
+type flowResult int
+
+const (
+	flowPass flowResult = iota // the entry value is neither read nor overwritten
+	flowRead                   // the entry value may be read
+	flowDead                   // the entry value is overwritten, or the function returns, before any read
+)
+
+// entryValueDead reports whether the value param holds on entry to body is
+// never read: on every path through body, param is assigned to, or the
+// function returns, before param is referenced. Statements other than
+// assignments, blocks, if statements and returns count as a read if they
+// refer to param at all, and functions containing a goto are not analysed.
+func (v *unusedVisitor) entryValueDead(param *ast.Ident, body *ast.BlockStmt) bool {
+	if body == nil || !v.isVar(param) || v.namedResults[param] || containsGoto(body) {
+		return false
+	}
+	return v.flowStmts(param, body.List, true) != flowRead
+}
+
+// flowStmts returns the effect of stmtList on the entry value of param. top
+// is true for the statements of the function body itself, where := may
+// assign to a parameter rather than declare a new variable.
+func (v *unusedVisitor) flowStmts(param *ast.Ident, stmtList []ast.Stmt, top bool) flowResult {
+	for _, stmt := range stmtList {
+		if result := v.flowStmt(param, stmt, top); result != flowPass {
+			return result
+		}
+	}
+	return flowPass
+}
+
+func (v *unusedVisitor) flowStmt(param *ast.Ident, stmt ast.Stmt, top bool) flowResult {
+	switch s := stmt.(type) {
+	case *ast.AssignStmt:
+		if s.Tok != token.ASSIGN && s.Tok != token.DEFINE {
+			// n += 1 reads n, even if -write_only only records the write
+			if v.assignsTo(s.Lhs[0], param) {
+				return flowRead
+			}
+			break
+		}
+		if s.Tok == token.DEFINE && !top {
+			break
+		}
+		assigned := false
+		others := append([]ast.Expr(nil), s.Rhs...)
+		for _, left := range s.Lhs {
+			if v.assignsTo(left, param) {
+				assigned = true
+				continue
+			}
+			others = append(others, left)
+		}
+		if !assigned {
+			break
+		}
+		if v.references(param, nil, others) {
+			return flowRead
+		}
+		return flowDead
+
+	case *ast.IncDecStmt:
+		if v.assignsTo(s.X, param) {
+			return flowRead
+		}
+
+	case *ast.BlockStmt:
+		return v.flowStmts(param, s.List, false)
+
+	case *ast.IfStmt:
+		if v.references(param, []ast.Stmt{s.Init}, []ast.Expr{s.Cond}) {
+			return flowRead
+		}
+		body := v.flowStmts(param, s.Body.List, false)
+		els := flowPass
+		if s.Else != nil {
+			els = v.flowStmt(param, s.Else, false)
+		}
+		switch {
+		case body == flowRead || els == flowRead:
+			return flowRead
+		case body == flowDead && els == flowDead:
+			return flowDead
+		default:
+			// the entry value is still live on at least one path
+			return flowPass
+		}
+
+	case *ast.ReturnStmt:
+		if v.references(param, nil, s.Results) {
+			return flowRead
+		}
+		return flowDead
+
+	case *ast.ExprStmt:
+		if call, ok := s.X.(*ast.CallExpr); ok && isPanic(call) && !v.references(param, nil, call.Args) {
+			return flowDead
+		}
+	}
+
+	if v.references(param, []ast.Stmt{stmt}, nil) {
+		return flowRead
+	}
+	return flowPass
+}
+
+// references reports whether stmtList or exprList refer to param, as
+// decided by handleStmts. Passing param to a call reads its entry value, so
+// arguments are not treated as recursive pass-throughs here, and function
+// literals are only searched for param, their own parameters having been
+// analysed already.
+func (v *unusedVisitor) references(param *ast.Ident, stmtList []ast.Stmt, exprList []ast.Expr) bool {
+	recursive, probing := v.recursive, v.probing
+	v.recursive, v.probing = false, true
+	defer func() { v.recursive, v.probing = recursive, probing }()
+
+	paramMap := map[*ast.Ident]bool{param: false}
+	v.handleStmts(paramMap, v.handleExprs(paramMap, exprList, stmtList))
+	return paramMap[param]
+}
+
+// assignsTo reports whether the assignment target expr is param itself.
+func (v *unusedVisitor) assignsTo(expr ast.Expr, param *ast.Ident) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && v.refersTo(ident, param)
+}
+
+// isVar reports whether param declares a variable rather than a type
+// parameter.
+func (v *unusedVisitor) isVar(param *ast.Ident) bool {
+	if v.info != nil {
+		_, ok := v.info.Defs[param].(*types.Var)
+		return ok
+	}
+	obj := v.defs[param]
+	return obj != nil && obj.kind == objVar
+}
+
+// containsGoto reports whether body contains a goto statement.
+func containsGoto(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if branch, ok := n.(*ast.BranchStmt); ok && branch.Tok == token.GOTO {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isPanic reports whether call is a call to the panic builtin.
+func isPanic(call *ast.CallExpr) bool {
+	ident, ok := call.Fun.(*ast.Ident)
+	return ok && ident.Name == "panic"
+}
@@ -1,3 +1,16 @@
 module github.com/alexkohler/nargs
 
-go 1.21
+go 1.25.0
+
+// x/tools v0.44.0 is the first release whose go/packages loads packages with
+// types (-typed) using Go 1.27: earlier ones fail with "package fmt without
+// types was imported". It requires go 1.25.0.
+require (
+	golang.org/x/tools v0.44.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	golang.org/x/mod v0.35.0 // indirect
+	golang.org/x/sync v0.20.0 // indirect
+)
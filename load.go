@@ -0,0 +1,473 @@
+package nargs
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	pwd = "."
+)
+
+// sourceFile is a Go file queued for analysis.
+type sourceFile struct {
+	filename string
+	src      []byte      // contents from the overlay, nil to read the file
+	file     *ast.File   // nil until parsed
+	info     *types.Info // nil unless type checked
+	flags    *Flags      // set by configuration files, nil for those of the run
+}
+
+// parse parses sf into fset unless it has already been parsed.
+func (sf *sourceFile) parse(fset *token.FileSet) error {
+	if sf.file != nil {
+		return nil
+	}
+	var src interface{}
+	if sf.src != nil {
+		src = sf.src
+	}
+	f, err := parser.ParseFile(fset, sf.filename, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return err
+	}
+	sf.file = f
+	return nil
+}
+
+// packageKey identifies the package sf belongs to: its directory and
+// package name. sf must have been parsed.
+func (sf *sourceFile) packageKey() string {
+	return filepath.Dir(sf.filename) + " " + sf.file.Name.Name
+}
+
+// parseInput resolves the files/packages contained in args into the files
+// to analyse, each listed once however many arguments it is reachable
+// through. In typed mode the files are parsed and type checked up front;
+// otherwise parsing is left to sourceFile.parse so it can be spread across
+// workers.
+func parseInput(ctx context.Context, cfg Config, fset *token.FileSet) ([]*sourceFile, error) {
+	if cfg.Flags.Typed {
+		return loadTypedInput(ctx, cfg, fset)
+	}
+
+	filenames, err := inputFiles(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*sourceFile, 0, len(filenames))
+	for _, filename := range filenames {
+		files = append(files, &sourceFile{
+			filename: filename,
+			src:      cfg.Overlay[absPath(filename)],
+		})
+	}
+	return files, nil
+}
+
+// inputFiles returns the sorted, de-duplicated names of the Go files
+// contained in args. Go files named in args are used as is; everything else
+// is resolved by go/packages, so directories, import paths and ... patterns
+// follow the rules of the go command, modules and vendoring included. Files
+// excluded by build constraints are kept, as nargs analyses every file
+// regardless of the platform it is built for.
+func inputFiles(ctx context.Context, cfg Config) ([]string, error) {
+	flags := cfg.Flags
+	files, patterns, err := splitArgs(cfg.Args, cfg.Overlay)
+	if err != nil {
+		return nil, err
+	}
+
+	var filenames []string
+	seen := make(map[string]bool)
+	add := func(filename string) {
+		if !strings.HasSuffix(filename, ".go") || (!flags.IncludeTests && isTestFile(filename)) {
+			return
+		}
+		key := absPath(filename)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		filenames = append(filenames, filename)
+	}
+
+	for _, filename := range files {
+		add(filepath.Clean(filename))
+	}
+
+	if len(patterns) != 0 && noMainModule(ctx) {
+		// go list cannot resolve directories outside a module: read them
+		// directly, leaving other patterns, e.g. of the standard library,
+		// to go list
+		var rest []string
+		for _, pattern := range patterns {
+			if !isLocalPattern(pattern) {
+				rest = append(rest, pattern)
+				continue
+			}
+			dirFiles, err := patternFiles(pattern)
+			if err != nil {
+				return nil, err
+			}
+			for _, filename := range dirFiles {
+				add(filename)
+			}
+		}
+		patterns = rest
+	}
+
+	if len(patterns) != 0 {
+		pkgs, err := packages.Load(packagesConfig(ctx, cfg, packages.NeedName|packages.NeedFiles), patterns...)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			if len(pkg.GoFiles) == 0 && len(pkg.IgnoredFiles) == 0 && len(pkg.Errors) != 0 {
+				return nil, pkg.Errors[0]
+			}
+		}
+		warnUnmatched(patterns, pkgs)
+		for _, pkg := range pkgs {
+			for _, filename := range pkg.GoFiles {
+				add(displayPath(filename))
+			}
+			for _, filename := range pkg.IgnoredFiles {
+				add(displayPath(filename))
+			}
+		}
+	}
+
+	sort.Strings(filenames)
+	return filenames, nil
+}
+
+// splitArgs separates the Go files named in args, which must exist on disk
+// or in overlay, from the directories, import paths and patterns, which
+// are converted into go/packages patterns.
+func splitArgs(args []string, overlay map[string][]byte) (files []string, patterns []string, _ error) {
+	if len(args) == 0 {
+		return nil, []string{pwd}, nil
+	}
+
+	for _, arg := range args {
+		if strings.HasSuffix(arg, ".go") {
+			if _, ok := overlay[absPath(arg)]; !ok {
+				if _, err := os.Stat(arg); err != nil {
+					return nil, nil, err
+				}
+			}
+			files = append(files, arg)
+			continue
+		}
+
+		dir := strings.TrimSuffix(arg, "/...")
+		if isDir(dir) && !filepath.IsAbs(dir) && !strings.HasPrefix(dir, ".") {
+			// go list treats "foo" as an import path, "./foo" as a directory.
+			arg = "./" + arg
+		}
+		patterns = append(patterns, arg)
+	}
+	return files, patterns, nil
+}
+
+// noMainModule reports whether the go command runs in module mode outside
+// of any module or workspace, where go list only accepts files and import
+// paths of the standard library.
+func noMainModule(ctx context.Context) bool {
+	out, err := exec.CommandContext(ctx, "go", "env", "GOMOD", "GOWORK").Output()
+	if err != nil {
+		return false
+	}
+	env := strings.Split(string(out), "\n")
+	if len(env) < 2 {
+		return false
+	}
+	gomod, gowork := env[0], env[1]
+	return gomod == os.DevNull && (gowork == "" || gowork == "off")
+}
+
+// isLocalPattern reports whether pattern is a directory, optionally
+// followed by /..., rather than an import path.
+func isLocalPattern(pattern string) bool {
+	dir := strings.TrimSuffix(pattern, "/...")
+	return !strings.Contains(dir, "...") && (build.IsLocalImport(dir) || filepath.IsAbs(dir))
+}
+
+// patternFiles returns the Go files of the directory named by the local
+// pattern, and with /... those of its subdirectories, skipping the
+// testdata, vendor, .foo and _foo directory trees as the go command does.
+func patternFiles(pattern string) ([]string, error) {
+	dir, recursive := strings.CutSuffix(pattern, "/...")
+	if !recursive {
+		files, err := dirGoFiles(dir)
+		if err == nil && len(files) == 0 {
+			err = fmt.Errorf("no Go files in %s", dir)
+		}
+		return files, err
+	}
+
+	var files []string
+	err := filepath.WalkDir(dir, func(filename string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if name := d.Name(); filename != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+			return filepath.SkipDir
+		}
+		dirFiles, err := dirGoFiles(filename)
+		files = append(files, dirFiles...)
+		return err
+	})
+	if err == nil && len(files) == 0 {
+		log.Printf("WARNING: %q matched no packages\n", pattern)
+	}
+	return files, err
+}
+
+// dirGoFiles returns the Go files of dir, ignoring those the go command
+// ignores, whose names start with . or _.
+func dirGoFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	return files, nil
+}
+
+// filesByDir groups files by directory, as go list only accepts files from a
+// single directory at a time.
+func filesByDir(files []string) [][]string {
+	var groups [][]string
+	groupByDir := make(map[string]int)
+	for _, filename := range files {
+		dir := filepath.Dir(filename)
+		index, ok := groupByDir[dir]
+		if !ok {
+			index = len(groups)
+			groupByDir[dir] = index
+			groups = append(groups, nil)
+		}
+		groups[index] = append(groups[index], filename)
+	}
+	return groups
+}
+
+func packagesConfig(ctx context.Context, cfg Config, mode packages.LoadMode) *packages.Config {
+	pkgCfg := &packages.Config{
+		Context: ctx,
+		Mode:    mode,
+		Tests:   cfg.Flags.IncludeTests,
+		Overlay: cfg.Overlay,
+	}
+	if cfg.Flags.Mod != "" {
+		pkgCfg.BuildFlags = append(pkgCfg.BuildFlags, "-mod="+cfg.Flags.Mod)
+	}
+	return pkgCfg
+}
+
+// absPath returns the absolute form of filename, as used for overlay keys.
+func absPath(filename string) string {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return filename
+	}
+	return abs
+}
+
+// absOverlay returns a copy of overlay keyed by absolute file names.
+func absOverlay(overlay map[string][]byte) map[string][]byte {
+	if len(overlay) == 0 {
+		return nil
+	}
+	abs := make(map[string][]byte, len(overlay))
+	for filename, src := range overlay {
+		abs[absPath(filename)] = src
+	}
+	return abs
+}
+
+// displayPath returns filename relative to the working directory if it is
+// located beneath it, so that findings read the same as for files named
+// on the command line.
+func displayPath(filename string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return filename
+	}
+	rel, err := filepath.Rel(wd, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename
+	}
+	return rel
+}
+
+// loadTypedInput loads and type checks the files/packages contained in args.
+// Each file is returned once, along with the type information of the
+// package it was checked as part of.
+func loadTypedInput(ctx context.Context, cfg Config, fset *token.FileSet) ([]*sourceFile, error) {
+	flags := cfg.Flags
+	pkgCfg := packagesConfig(ctx, cfg, packages.NeedName|packages.NeedFiles|packages.NeedImports|packages.NeedSyntax|packages.NeedTypes|packages.NeedTypesInfo)
+	pkgCfg.Fset = fset
+	pkgCfg.ParseFile = func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+		return parser.ParseFile(fset, displayPath(filename), src, parser.ParseComments|parser.SkipObjectResolution)
+	}
+
+	files, patterns, err := splitArgs(cfg.Args, cfg.Overlay)
+	if err != nil {
+		return nil, err
+	}
+	loads := filesByDir(files)
+	if len(patterns) != 0 {
+		loads = append(loads, patterns)
+	}
+
+	var typedFiles []*sourceFile
+	seen := make(map[string]bool)
+	for _, load := range loads {
+		pkgs, err := packages.Load(pkgCfg, load...)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			if !strings.HasSuffix(pkg.ID, ".test") && len(pkg.Syntax) == 0 && len(pkg.Errors) != 0 {
+				return nil, pkg.Errors[0]
+			}
+		}
+		warnUnmatched(load, pkgs)
+
+		for _, pkg := range pkgs {
+			if strings.HasSuffix(pkg.ID, ".test") {
+				// generated test main package
+				continue
+			}
+			for _, pkgErr := range pkg.Errors {
+				// Analysis can still proceed with partial type information.
+				log.Printf("WARNING: %v\n", pkgErr)
+			}
+
+			for _, f := range pkg.Syntax {
+				filename := fset.File(f.Pos()).Name()
+				if seen[filename] || (!flags.IncludeTests && isTestFile(filename)) {
+					continue
+				}
+				seen[filename] = true
+				typedFiles = append(typedFiles, &sourceFile{
+					filename: filename,
+					file:     f,
+					info:     pkg.TypesInfo,
+				})
+			}
+		}
+	}
+
+	return typedFiles, nil
+}
+
+// warnUnmatched logs a warning for each ... pattern of patterns matching
+// none of pkgs, the packages loaded for patterns, as go list silently
+// drops them. Other patterns not matching a package are errors.
+func warnUnmatched(patterns []string, pkgs []*packages.Package) {
+	for _, pattern := range unmatchedPatterns(patterns, pkgs) {
+		log.Printf("WARNING: %q matched no packages\n", pattern)
+	}
+}
+
+// unmatchedPatterns returns the ... patterns of patterns matching none of
+// pkgs, by import path, or by directory for patterns that are file system
+// paths.
+func unmatchedPatterns(patterns []string, pkgs []*packages.Package) []string {
+	var unmatched []string
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "...") {
+			continue
+		}
+		local := build.IsLocalImport(pattern) || filepath.IsAbs(pattern)
+		match := matchPackagePattern(pattern)
+		if local {
+			match = matchPackagePattern(filepath.ToSlash(absPath(pattern)))
+		}
+		matched := false
+		for _, pkg := range pkgs {
+			name := pkg.PkgPath
+			if local {
+				name = filepath.ToSlash(pkg.Dir)
+			}
+			if match(name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, pattern)
+		}
+	}
+	return unmatched
+}
+
+// matchPackagePattern returns a function reporting whether a slash-separated
+// import path or directory matches pattern, in which ... matches any
+// string, and a trailing /... also matches the empty string. As for the go
+// command, directories named testdata or starting with . or _ are not
+// matched by ..., the directory it starts in included.
+func matchPackagePattern(pattern string) func(name string) bool {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	if strings.HasSuffix(re, `/.*`) {
+		re = strings.TrimSuffix(re, `/.*`) + `(/.*)?`
+	}
+	reg := regexp.MustCompile(`^` + re + `$`)
+
+	// the directory the wildcard starts in, and the one containing it
+	root := path.Dir(pattern[:strings.Index(pattern, "...")] + "x")
+	parent := path.Dir(root)
+	return func(name string) bool {
+		if !reg.MatchString(name) {
+			return false
+		}
+		rel := name
+		if root != "." && root != "/" {
+			rel = strings.TrimPrefix(name, parent)
+		}
+		for _, elem := range strings.Split(rel, "/") {
+			if elem == "testdata" || strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_") {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func isTestFile(filename string) bool {
+	return strings.HasSuffix(filename, "test.go")
+}
+
+func isDir(filename string) bool {
+	fi, err := os.Stat(filename)
+	return err == nil && fi.IsDir()
+}
@@ -0,0 +1,118 @@
+package nargs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func TestInputFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "File reachable through several arguments",
+			args: []string{"testdata/pkg/pkg.go", "testdata/pkg", "./testdata/pkg/..."},
+			want: []string{
+				"testdata/pkg/pkg.go",
+				"testdata/pkg/pkg_test.go",
+			},
+		},
+		{
+			name: "Import path pattern",
+			args: []string{"github.com/alexkohler/nargs/cmd/..."},
+			want: []string{"cmd/nargs/main.go"},
+		},
+		{
+			name:    "Missing file",
+			args:    []string{"testdata/nosuchfile.go"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown package",
+			args:    []string{"github.com/alexkohler/nargs/nosuchpkg"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inputFiles(context.Background(), Config{Args: tt.args, Flags: Flags{IncludeTests: true}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("inputFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inputFiles()\ngot = %v,\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInputFilesOutsideModule(t *testing.T) {
+	dir := t.TempDir()
+	for _, filename := range []string{"pkg/x.go", "pkg/x_test.go", "pkg/testdata/y.go", "pkg/_z.go", "sub/deep/z.go"} {
+		filename = filepath.Join(dir, filename)
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte("package p\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+	t.Setenv("GO111MODULE", "on")
+	t.Setenv("GOWORK", "off")
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "Directory",
+			args: []string{"pkg"},
+			want: []string{"pkg/x.go", "pkg/x_test.go"},
+		},
+		{
+			name: "All packages",
+			args: []string{"./..."},
+			want: []string{"pkg/x.go", "pkg/x_test.go", "sub/deep/z.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inputFiles(context.Background(), Config{Args: tt.args, Flags: Flags{IncludeTests: true}})
+			if err != nil {
+				t.Fatalf("inputFiles() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inputFiles()\ngot = %v,\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmatchedPatterns(t *testing.T) {
+	patterns := []string{
+		"./testdata/...",
+		"./testdata/pkg/...",
+		"github.com/alexkohler/nargs/cmd/...",
+		"github.com/alexkohler/nargs/nosuchpkg/...",
+		"./cmd/nargs",
+	}
+	pkgs, err := packages.Load(packagesConfig(context.Background(), Config{}, packages.NeedName|packages.NeedFiles), patterns...)
+	if err != nil {
+		t.Fatalf("packages.Load() error = %v", err)
+	}
+	got := unmatchedPatterns(patterns, pkgs)
+	want := []string{"./testdata/...", "github.com/alexkohler/nargs/nosuchpkg/..."}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unmatchedPatterns() = %q, want %q", got, want)
+	}
+}
@@ -3,98 +3,372 @@ package nargs
 import (
 	"fmt"
 	"go/ast"
-	"go/build"
 	"go/token"
+	"go/types"
 	"log"
+	"path"
 	"sort"
-	"strconv"
-	"strings"
 )
 
-func init() {
-	build.Default.UseAllFiles = true
-}
-
 // Flags contains configuration specific to nargs
 // * IncludeTests - include test files in analysis
 // * SetExitStatus - set exit status to 1 if any issues are found
 // * IncludeNamedReturns - include unused named returns
 // * IncludeReceivers - include unused receivers
+// * ReceiversByType - report unused receivers per type rather than per method, implies IncludeReceivers
+// * IncludeTypeParams - include unused type parameters of generic functions
+// * IncludeReceiverTypeParams - include unused type parameters of generic receivers
+// * WriteOnly - report parameters that are assigned to but never read
+// * Overwritten - report parameters overwritten before their value is read
+// * Recursive - report parameters only passed on to recursive calls
+// * MutualRecursion - with Recursive, follow calls to other functions of the package
+// * DeadCode - do not count uses in unreachable code
+// * BlankAssign - treatment of _ = param: "use" (the default), "suppress" or "report"
+// * ShowSuppressed - include suppressed findings, e.g. to audit them
+// * StaleDirectives - report nargs directives that suppress no finding
+// * Typed - resolve identifiers using full type information
+// * Mod - module download mode passed to go list (readonly, vendor or mod)
+// * Jobs - number of files parsed and analysed in parallel, GOMAXPROCS if <= 0
+// * Allow - parameter name patterns (path.Match) whose findings are suppressed
+// * Kinds - names of the finding kinds to report, enabling them; all if empty
+// * Severity - severity of the findings: "error" (the default) or "warning"
+//
+// Flags are read from and written to configuration files (see ConfigFile)
+// under the names of the command line flags, e.g. named_returns.
 type Flags struct {
-	IncludeTests        bool
-	SetExitStatus       bool
-	IncludeNamedReturns bool
-	IncludeReceivers    bool
+	IncludeTests              bool     `yaml:"tests" json:"tests"`
+	SetExitStatus             bool     `yaml:"set_exit_status" json:"set_exit_status"`
+	IncludeNamedReturns       bool     `yaml:"named_returns" json:"named_returns"`
+	IncludeReceivers          bool     `yaml:"receivers" json:"receivers"`
+	ReceiversByType           bool     `yaml:"receivers_by_type" json:"receivers_by_type"`
+	IncludeTypeParams         bool     `yaml:"type_params" json:"type_params"`
+	IncludeReceiverTypeParams bool     `yaml:"receiver_type_params" json:"receiver_type_params"`
+	WriteOnly                 bool     `yaml:"write_only" json:"write_only"`
+	Overwritten               bool     `yaml:"overwritten" json:"overwritten"`
+	Recursive                 bool     `yaml:"recursive" json:"recursive"`
+	MutualRecursion           bool     `yaml:"mutual_recursion" json:"mutual_recursion"`
+	DeadCode                  bool     `yaml:"dead_code" json:"dead_code"`
+	BlankAssign               string   `yaml:"blank_assign,omitempty" json:"blank_assign,omitempty"`
+	ShowSuppressed            bool     `yaml:"show_suppressed" json:"show_suppressed"`
+	StaleDirectives           bool     `yaml:"stale_directives" json:"stale_directives"`
+	Typed                     bool     `yaml:"typed" json:"typed"`
+	Mod                       string   `yaml:"mod,omitempty" json:"mod,omitempty"`
+	Jobs                      int      `yaml:"jobs,omitempty" json:"jobs,omitempty"`
+	Allow                     []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+	Kinds                     []string `yaml:"kinds,omitempty" json:"kinds,omitempty"`
+	Severity                  string   `yaml:"severity,omitempty" json:"severity,omitempty"`
+}
+
+// Policies for Flags.BlankAssign.
+const (
+	// BlankAssignUse counts _ = param as a use of param.
+	BlankAssignUse = "use"
+	// BlankAssignSuppress treats _ = param as acknowledging that param is
+	// unused: it is reported as suppressed.
+	BlankAssignSuppress = "suppress"
+	// BlankAssignReport reports parameters only used in _ = param, which
+	// should be renamed to _ instead.
+	BlankAssignReport = "report"
+)
+
+// validate reports an error if flags has an invalid setting.
+func (flags Flags) validate() error {
+	switch flags.BlankAssign {
+	case "", BlankAssignUse, BlankAssignSuppress, BlankAssignReport:
+	default:
+		return fmt.Errorf("invalid blank assignment policy %q, must be %v, %v or %v", flags.BlankAssign, BlankAssignUse, BlankAssignSuppress, BlankAssignReport)
+	}
+	switch flags.Severity {
+	case "", SeverityError, SeverityWarning:
+	default:
+		return fmt.Errorf("invalid severity %q, must be %v or %v", flags.Severity, SeverityError, SeverityWarning)
+	}
+	for _, pattern := range flags.Allow {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid allowed parameter name pattern %q, %v", pattern, err)
+		}
+	}
+	for _, name := range flags.Kinds {
+		if _, ok := parseKind(name); !ok {
+			return fmt.Errorf("invalid finding kind %q", name)
+		}
+	}
+	return nil
+}
+
+// Severities for Flags.Severity.
+const (
+	// SeverityError findings set the exit status with Flags.SetExitStatus.
+	SeverityError = "error"
+	// SeverityWarning findings are reported but do not set the exit status.
+	SeverityWarning = "warning"
+)
+
+// kinds returns the kinds listed in flags.Kinds, or nil if it is empty, and
+// flags with the options reporting each kind enabled if it is listed and
+// disabled otherwise.
+func (flags Flags) kinds() (map[Kind]bool, Flags) {
+	if len(flags.Kinds) == 0 {
+		return nil, flags
+	}
+	kinds := make(map[Kind]bool)
+	for _, name := range flags.Kinds {
+		kind, _ := parseKind(name)
+		kinds[kind] = true
+	}
+	flags.IncludeReceivers = kinds[KindReceiver]
+	flags.ReceiversByType = kinds[KindReceiverType]
+	flags.IncludeNamedReturns = kinds[KindNamedReturn]
+	flags.IncludeTypeParams = kinds[KindTypeParam]
+	flags.IncludeReceiverTypeParams = kinds[KindReceiverTypeParam]
+	flags.StaleDirectives = kinds[KindDirective]
+	return kinds, flags
 }
 
 type unusedVisitor struct {
-	fileSet             *token.FileSet
-	currentFile         *token.File
-	resultsSet          map[string]struct{}
-	includeNamedReturns bool
-	includeReceivers    bool
-	errsFound           bool
+	fileSet                   *token.FileSet
+	currentFile               *token.File
+	results                   map[resultKey]unusedParam
+	closureNames              map[*ast.FuncLit]string
+	funcLitDepth              int                   // function literals being walked with the parameters of an enclosing function
+	probing                   bool                  // walking for references only, see references
+	accesses                  map[*ast.Ident]access // how parameters are referenced besides being read
+	namedResults              map[*ast.Ident]bool
+	currentFunc               *ast.FuncDecl // the declaration being visited, if any
+	passedTo                  map[*ast.Ident]map[funcParam]bool
+	passThroughs              []passThrough
+	methods                   []method                    // with -receivers_by_type
+	deadUses                  map[*ast.Ident]token.Pos    // first unreachable statement using each parameter
+	blankUses                 map[*ast.Ident]token.Pos    // first _ = param assignment of each parameter
+	info                      *types.Info                 // nil unless running in typed mode
+	declIdents                map[types.Object]*ast.Ident // declaring identifiers of the file, in typed mode
+	defs                      map[*ast.Ident]*object      // objects declared, unless running in typed mode
+	uses                      map[*ast.Ident]*object      // objects referred to, unless running in typed mode
+	suppressions              []suppression               // ranges suppressed by directives
+	directives                []*ast.Comment              // nargs directives, stale unless matched
+	matchedDirectives         map[token.Pos]bool          // directives covering a finding
+	includeNamedReturns       bool
+	includeReceivers          bool
+	receiversByType           bool
+	includeTypeParams         bool
+	includeReceiverTypeParams bool
+	writeOnly                 bool
+	overwritten               bool
+	recursive                 bool
+	mutualRecursion           bool
+	deadCode                  bool
+	blankAssign               string
+	showSuppressed            bool
+	reportStale               bool
+	allow                     []string      // parameter name patterns
+	kinds                     map[Kind]bool // kinds to report, all if nil
+	severity                  string
 }
 
+// access records the ways a parameter is referenced other than being read.
+type access uint8
+
+const (
+	accessWrite       access = 1 << iota // assigned to, incremented or decremented
+	accessNakedReturn                    // a named result returned by a naked return
+)
+
 // CheckForUnusedFunctionArgs will parse the files/packages contained in args
-// and walk the AST searching for unused function parameters.
+// and walk the AST searching for unused function parameters. Each result is
+// the String rendering of a Finding followed by a newline. Suppressed
+// findings, listed with flags.ShowSuppressed, and warnings do not set
+// exitWithStatus. Configuration files are not read; see Config.Check.
 func CheckForUnusedFunctionArgs(args []string, flags Flags) (results []string, exitWithStatus bool, _ error) {
-	fset := token.NewFileSet()
-	files, err := parseInput(args, fset, flags.IncludeTests)
-	if err != nil {
-		return nil, false, fmt.Errorf("could not parse input, %v", err)
+	return Config{Args: args, Flags: flags}.Check()
+}
+
+// Analyze will parse the files/packages contained in args and walk the AST
+// searching for unused function parameters. Files are parsed and analysed
+// by flags.Jobs workers in parallel; the findings are sorted as defined by
+// Finding.Less.
+func Analyze(args []string, flags Flags) ([]Finding, error) {
+	return Config{Args: args, Flags: flags}.Analyze()
+}
+
+// analyzeFile parses sf if it has not been parsed yet and returns the
+// unused parameters it contains. With -mutual_recursion, the parameters only
+// passed on to other functions of the package are returned separately, as
+// whether they are unused depends on the rest of the package; so are the
+// methods with -receivers_by_type.
+func (v *unusedVisitor) analyzeFile(sf *sourceFile) ([]Finding, packageFacts, error) {
+	if err := sf.parse(v.fileSet); err != nil {
+		return nil, packageFacts{}, err
 	}
 
-	retVis := &unusedVisitor{
-		fileSet:             fset,
-		includeNamedReturns: flags.IncludeNamedReturns,
-		includeReceivers:    flags.IncludeReceivers,
-		resultsSet:          make(map[string]struct{}),
+	v.info = sf.info
+	v.defs, v.uses, v.declIdents = nil, nil, nil
+	if v.info == nil {
+		v.defs, v.uses = resolveFile(sf.file)
+	} else {
+		v.declIdents = declIdents(v.info, sf.file)
+	}
+	v.suppressions, v.directives = fileDirectives(v.fileSet, sf.file)
+	v.matchedDirectives = make(map[token.Pos]bool)
+	v.results = make(map[resultKey]unusedParam)
+	v.accesses = make(map[*ast.Ident]access)
+	v.namedResults = make(map[*ast.Ident]bool)
+	v.passedTo = make(map[*ast.Ident]map[funcParam]bool)
+	v.passThroughs = nil
+	v.methods = nil
+	v.deadUses = make(map[*ast.Ident]token.Pos)
+	v.blankUses = make(map[*ast.Ident]token.Pos)
+	ast.Walk(v, sf.file)
+
+	findings := make([]Finding, 0, len(v.results))
+	for _, result := range v.results {
+		findings = append(findings, result.finding(v.fileSet))
+	}
+	if v.reportStale {
+		findings = append(findings, v.staleDirectives()...)
 	}
 
-	// visitorResult contains the results for a specific visitor and is cleared on each
-	// iteration
-	var visitorResult []string
-	for _, f := range files {
-		if f == nil {
-			continue
-		}
-		ast.Walk(retVis, f)
-		for result := range retVis.resultsSet {
-			visitorResult = append(visitorResult, result)
-		}
-		// Due to our analysis, of the ast.File, we may end up getting our results out of order. Sort by line number to keep
-		// the results in a consistent format.
-		sort.Sort(byLineNumber(visitorResult))
-		results = append(results, visitorResult...)
-		visitorResult = nil
-		retVis.resultsSet = make(map[string]struct{})
+	facts := packageFacts{methods: v.methods}
+	if v.mutualRecursion {
+		facts.passThroughs = v.passThroughs
+		return findings, facts, nil
 	}
+	for _, result := range unusedPassThroughs(v.passThroughs) {
+		findings = append(findings, result.finding(v.fileSet))
+	}
+	return findings, facts, nil
+}
 
-	return results, retVis.errsFound && flags.SetExitStatus, nil
+// unusedParam describes a parameter that is never used by the function
+// declaring it.
+type unusedParam struct {
+	funcName     string
+	funcPos      token.Pos
+	recv         string
+	ident        *ast.Ident
+	kind         Kind
+	reason       Reason
+	related      token.Pos // see Finding.Related
+	suppressedBy string
+	expiredBy    string
+	directive    token.Pos // the directive covering the parameter, if any
+	severity     string
 }
 
-// ugly, but not sure if there's another option..
-type byLineNumber []string
+func (p unusedParam) finding(fset *token.FileSet) Finding {
+	return Finding{
+		Pos:          fset.Position(p.ident.Pos()),
+		End:          fset.Position(p.ident.End()),
+		FuncPos:      fset.Position(p.funcPos),
+		Func:         p.funcName,
+		Recv:         p.recv,
+		Param:        p.ident.Name,
+		Kind:         p.kind,
+		Reason:       p.reason,
+		Related:      fset.Position(p.related),
+		SuppressedBy: p.suppressedBy,
+		ExpiredBy:    p.expiredBy,
+		Severity:     p.severity,
+	}
+}
 
-func (a byLineNumber) Len() int { return len(a) }
-func (a byLineNumber) Less(i, j int) bool {
-	iLine := strings.Split(a[i], ":")
-	num := strings.Split(iLine[1], " ")
-	iNum, _ := strconv.Atoi(num[0])
+func newUnusedVisitor(fset *token.FileSet, flags Flags) *unusedVisitor {
+	kinds, flags := flags.kinds()
+	return &unusedVisitor{
+		fileSet:                   fset,
+		includeNamedReturns:       flags.IncludeNamedReturns,
+		includeReceivers:          flags.IncludeReceivers || flags.ReceiversByType,
+		receiversByType:           flags.ReceiversByType,
+		includeTypeParams:         flags.IncludeTypeParams,
+		includeReceiverTypeParams: flags.IncludeReceiverTypeParams,
+		writeOnly:                 flags.WriteOnly,
+		overwritten:               flags.Overwritten,
+		recursive:                 flags.Recursive || flags.MutualRecursion,
+		mutualRecursion:           flags.MutualRecursion,
+		deadCode:                  flags.DeadCode,
+		blankAssign:               flags.BlankAssign,
+		showSuppressed:            flags.ShowSuppressed,
+		reportStale:               flags.StaleDirectives,
+		allow:                     flags.Allow,
+		kinds:                     kinds,
+		severity:                  flags.Severity,
+		matchedDirectives:         make(map[token.Pos]bool),
+		results:                   make(map[resultKey]unusedParam),
+		accesses:                  make(map[*ast.Ident]access),
+		namedResults:              make(map[*ast.Ident]bool),
+		passedTo:                  make(map[*ast.Ident]map[funcParam]bool),
+		deadUses:                  make(map[*ast.Ident]token.Pos),
+		blankUses:                 make(map[*ast.Ident]token.Pos),
+	}
+}
+
+// report records an unused parameter. Suppressed parameters are only
+// recorded with -show_suppressed.
+func (v *unusedVisitor) report(result unusedParam) {
+	v.matched(result)
+	if (result.suppressedBy != "" && !v.showSuppressed) || !v.reports(result.kind) {
+		return
+	}
+	key := resultKey{ident: result.ident}
+	if result.reason == ReasonShadowedResult {
+		key.shadow = result.related
+	}
+	v.results[key] = result
+}
+
+// reports reports whether findings of kind are reported, as per Flags.Kinds.
+func (v *unusedVisitor) reports(kind Kind) bool {
+	return v.kinds == nil || v.kinds[kind]
+}
 
-	jLine := strings.Split(a[j], ":")
-	num = strings.Split(jLine[1], " ")
-	jNum, _ := strconv.Atoi(num[0])
-	return iNum < jNum
+// resultKey identifies a result. Function literals are walked both with the
+// file and with their enclosing declaration, so results are keyed by
+// parameter rather than collected in a list; a named result may however be
+// shadowed more than once.
+type resultKey struct {
+	ident  *ast.Ident
+	shadow token.Pos // the shadowing declaration, for ReasonShadowedResult
+}
+
+// resetParams forgets what is known about how the parameters declared by
+// fieldLists are accessed, before walking the function declaring them.
+// The file-level walk shares one paramMap across all the functions of the
+// file, so what is recorded about them must start afresh for each.
+func (v *unusedVisitor) resetParams(fieldLists ...*ast.FieldList) {
+	for _, fieldList := range fieldLists {
+		if fieldList == nil {
+			continue
+		}
+		for _, field := range fieldList.List {
+			for _, name := range field.Names {
+				delete(v.accesses, name)
+				delete(v.deadUses, name)
+				delete(v.blankUses, name)
+			}
+		}
+	}
+}
+
+// sortedResults returns the unused parameters found so far ordered by
+// position.
+func (v *unusedVisitor) sortedResults() []unusedParam {
+	sorted := make([]unusedParam, 0, len(v.results))
+	for _, result := range v.results {
+		sorted = append(sorted, result)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].ident != sorted[j].ident {
+			return sorted[i].ident.Pos() < sorted[j].ident.Pos()
+		}
+		return sorted[i].related < sorted[j].related
+	})
+	return sorted
 }
-func (a byLineNumber) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 
 // Visit implements the ast.Visitor Visit method.
 func (v *unusedVisitor) Visit(node ast.Node) ast.Visitor {
 	var stmtList []ast.Stmt
 	var file *token.File
-	paramMap := make(map[string]bool)
+	paramMap := make(map[*ast.Ident]bool)
 	var funcDecl *ast.FuncDecl
 
 	switch topLevelType := node.(type) {
@@ -108,10 +382,13 @@ func (v *unusedVisitor) Visit(node ast.Node) ast.Visitor {
 		stmtList = v.handleFuncDecl(paramMap, funcDecl, stmtList)
 		file = v.fileSet.File(funcDecl.Pos())
 		v.currentFile = file
+		v.currentFunc = funcDecl
+		defer func() { v.currentFunc = nil }()
 
 	case *ast.File:
 		file = v.fileSet.File(topLevelType.Pos())
 		v.currentFile = file
+		v.closureNames = closureNames(topLevelType)
 		if topLevelType.Decls != nil {
 			stmtList = v.handleDecls(paramMap, topLevelType.Decls, stmtList)
 		}
@@ -127,10 +404,8 @@ func (v *unusedVisitor) Visit(node ast.Node) ast.Visitor {
 	// Analyze body of function
 	v.handleStmts(paramMap, stmtList)
 
-	for paramName, used := range paramMap {
-		if used {
-			continue
-		}
+	unusedRecv := false
+	for param, used := range paramMap {
 		if file == nil {
 			continue
 		}
@@ -140,57 +415,125 @@ func (v *unusedVisitor) Visit(node ast.Node) ast.Visitor {
 		if funcDecl.Name == nil {
 			continue
 		}
+		kind := KindParam
+		switch {
+		case fieldListContains(funcDecl.Recv, param):
+			kind = KindReceiver
+		case fieldListContains(funcDecl.Type.Results, param):
+			kind = KindNamedReturn
+		case fieldListContains(funcDecl.Type.TypeParams, param):
+			kind = KindTypeParam
+		case containsIdent(recvTypeParams(funcDecl), param):
+			kind = KindReceiverTypeParam
+		}
+
+		result := unusedParam{
+			funcName: funcDecl.Name.Name,
+			funcPos:  funcDecl.Pos(),
+			recv:     recvTypeName(funcDecl),
+			ident:    param,
+			kind:     kind,
+			severity: v.severity,
+		}
+		v.suppress(&result)
+		for _, shadow := range v.shadowingResult(param, funcDecl.Body) {
+			// reported whether or not the named result is used
+			shadowed := result
+			shadowed.reason = ReasonShadowedResult
+			shadowed.related = shadow.Pos()
+			v.report(shadowed)
+		}
+		if targets := v.passedTo[param]; !used && len(targets) != 0 {
+			// only used by being passed on, which may or may not be to
+			// parameters that are used
+			index, _ := paramIndex(funcDecl, param)
+			result.reason = ReasonRecursive
+			candidate := passThrough{
+				param:  funcParam{fn: funcKey(funcDecl), index: index},
+				result: result,
+				hidden: !v.reports(kind),
+			}
+			for target := range targets {
+				candidate.targets = append(candidate.targets, target)
+			}
+			v.passThroughs = append(v.passThroughs, candidate)
+			v.matched(result)
+			continue
+		}
+
+		if !v.classify(&result, used, funcDecl.Body) {
+			continue
+		}
+		if kind == KindReceiver && v.receiversByType {
+			// reported with the other methods of the type
+			unusedRecv = unusedRecv || result.suppressedBy == ""
+			v.matched(result)
+			continue
+		}
 
 		// TODO print parameter vs parameter(s)?
-		// TODO differentiation of used parameter vs. receiver?
-		resStr := fmt.Sprintf(
-			"%v:%v %v contains unused parameter %v\n",
-			file.Name(),
-			file.Position(funcDecl.Pos()).Line,
-			funcDecl.Name.Name,
-			paramName,
-		)
-		v.resultsSet[resStr] = struct{}{}
-		v.errsFound = true
+		v.report(result)
+	}
+
+	if v.receiversByType && funcDecl != nil && funcDecl.Recv != nil {
+		m := newMethod(funcDecl, unusedRecv)
+		m.severity = v.severity
+		v.methods = append(v.methods, m)
 	}
 
 	return v
 }
 
-func (v *unusedVisitor) handleStmts(paramMap map[string]bool, stmtList []ast.Stmt) {
+func (v *unusedVisitor) handleStmts(paramMap map[*ast.Ident]bool, stmtList []ast.Stmt) {
 	for len(stmtList) != 0 {
 		stmt := stmtList[0]
 		switch s := stmt.(type) {
 		case *ast.IfStmt:
-			stmtList = append(stmtList, s.Init, s.Body, s.Else)
+			body, els := v.liveBranches(paramMap, s)
+			stmtList = append(stmtList, s.Init, body, els)
 			stmtList = v.handleExprs(paramMap, []ast.Expr{s.Cond}, stmtList)
 
 		case *ast.AssignStmt:
-			assigned := false
-			for index, right := range s.Rhs {
-				funcLit, ok := right.(*ast.FuncLit)
-				if !ok {
-					continue
-				}
-				funcName, ok := s.Lhs[index].(*ast.Ident)
-				if !ok {
-					// TODO - understand this case a little more
-					// log.Printf("@@@@@@@@@@@@@@@@@@@@@@@@@2 wat")
-					continue
-				}
-				v.handleFuncLit(paramMap, funcLit, funcName)
-				assigned = true
+			if v.blankAssign != "" && v.blankAssign != BlankAssignUse && isBlankAssign(s) {
+				stmtList = v.handleBlankAssign(paramMap, s, stmtList)
+				break
 			}
 
-			if !assigned {
-				stmtList = v.handleExprs(paramMap, s.Lhs, stmtList)
-				stmtList = v.handleExprs(paramMap, s.Rhs, stmtList)
+			lhs, rhs := s.Lhs, s.Rhs
+			if len(s.Lhs) == len(s.Rhs) {
+				lhs, rhs = nil, nil
+				for index, right := range s.Rhs {
+					left := s.Lhs[index]
+					funcLit, ok := right.(*ast.FuncLit)
+					if !ok {
+						lhs = append(lhs, left)
+						rhs = append(rhs, right)
+						continue
+					}
+					v.handleFuncLit(paramMap, funcLit, v.closureNames[funcLit])
+					if _, ok := left.(*ast.Ident); !ok {
+						// the target of a field or index assignment may
+						// itself use parameters, e.g. table[k] = func() {}
+						lhs = append(lhs, left)
+					}
+				}
 			}
 
+			stmtList = v.handleAssigned(paramMap, lhs, stmtList)
+			stmtList = v.handleExprs(paramMap, rhs, stmtList)
+
 		case *ast.BlockStmt:
-			stmtList = append(stmtList, s.List...)
+			stmtList = append(stmtList, v.liveStmts(paramMap, s.List)...)
 
 		case *ast.ReturnStmt:
+			// a naked return of a function literal returns its own results
+			if len(s.Results) == 0 && v.funcLitDepth == 0 {
+				for param := range paramMap {
+					if v.namedResults[param] {
+						v.accesses[param] |= accessNakedReturn
+					}
+				}
+			}
 			stmtList = v.handleExprs(paramMap, s.Results, stmtList)
 
 		case *ast.DeclStmt:
@@ -202,6 +545,9 @@ func (v *unusedVisitor) handleStmts(paramMap map[string]bool, stmtList []ast.Stm
 		case *ast.RangeStmt:
 			stmtList = append(stmtList, s.Body)
 			stmtList = v.handleExprs(paramMap, []ast.Expr{s.X}, stmtList)
+			if s.Tok == token.ASSIGN {
+				stmtList = v.handleAssigned(paramMap, []ast.Expr{s.Key, s.Value}, stmtList)
+			}
 
 		case *ast.ForStmt:
 			stmtList = append(stmtList, s.Init)
@@ -217,7 +563,7 @@ func (v *unusedVisitor) handleStmts(paramMap map[string]bool, stmtList []ast.Stm
 		case *ast.CaseClause:
 			stmtList = v.handleExprs(paramMap, s.List, stmtList)
 
-			stmtList = append(stmtList, s.Body...)
+			stmtList = append(stmtList, v.liveStmts(paramMap, s.Body)...)
 
 		case *ast.SendStmt:
 			stmtList = v.handleExprs(paramMap, []ast.Expr{s.Chan, s.Value}, stmtList)
@@ -226,28 +572,31 @@ func (v *unusedVisitor) handleStmts(paramMap map[string]bool, stmtList []ast.Stm
 			stmtList = v.handleExprs(paramMap, []ast.Expr{s.Call}, stmtList)
 
 		case *ast.DeferStmt:
+			if funcLit, ok := s.Call.Fun.(*ast.FuncLit); ok {
+				v.handleDeferredWrites(paramMap, funcLit)
+			}
 			stmtList = v.handleExprs(paramMap, []ast.Expr{s.Call}, stmtList)
 
 		case *ast.SelectStmt:
 			stmtList = append(stmtList, s.Body)
 
 		case *ast.CommClause:
-			stmtList = append(stmtList, s.Body...)
+			stmtList = append(stmtList, v.liveStmts(paramMap, s.Body)...)
 			stmtList = append(stmtList, s.Comm)
 
 		case *ast.BranchStmt:
-			handleIdent(paramMap, s.Label)
+			v.handleIdent(paramMap, s.Label)
 
 		case *ast.SwitchStmt:
 			stmtList = append(stmtList, s.Body, s.Init)
 			stmtList = v.handleExprs(paramMap, []ast.Expr{s.Tag}, stmtList)
 
 		case *ast.LabeledStmt:
-			handleIdent(paramMap, s.Label)
+			v.handleIdent(paramMap, s.Label)
 			stmtList = append(stmtList, s.Stmt)
 
 		case *ast.IncDecStmt:
-			stmtList = v.handleExprs(paramMap, []ast.Expr{s.X}, stmtList)
+			stmtList = v.handleAssigned(paramMap, []ast.Expr{s.X}, stmtList)
 
 		case nil, *ast.EmptyStmt:
 			// no-op
@@ -260,48 +609,244 @@ func (v *unusedVisitor) handleStmts(paramMap map[string]bool, stmtList []ast.Stm
 	}
 }
 
-func handleIdents(paramMap map[string]bool, identList []*ast.Ident) {
+func (v *unusedVisitor) handleIdents(paramMap map[*ast.Ident]bool, identList []*ast.Ident) {
 	for _, ident := range identList {
-		handleIdent(paramMap, ident)
+		v.handleIdent(paramMap, ident)
 	}
 }
 
-func handleIdent(paramMap map[string]bool, ident *ast.Ident) {
+func (v *unusedVisitor) handleIdent(paramMap map[*ast.Ident]bool, ident *ast.Ident) {
 	if ident == nil {
 		return
 	}
 
-	if ident.Obj != nil && ident.Obj.Kind == ast.Var {
-		if _, ok := paramMap[ident.Obj.Name]; ok {
-			paramMap[ident.Obj.Name] = true
+	if param := v.paramOf(paramMap, ident); param != nil {
+		paramMap[param] = true
+	}
+}
+
+// handleBlankAssign handles _ = param, recording the parameters assigned
+// to the blank identifier rather than counting them as used.
+func (v *unusedVisitor) handleBlankAssign(paramMap map[*ast.Ident]bool, assign *ast.AssignStmt, stmtList []ast.Stmt) []ast.Stmt {
+	for _, right := range assign.Rhs {
+		ident, ok := ast.Unparen(right).(*ast.Ident)
+		if !ok {
+			stmtList = v.handleExprs(paramMap, []ast.Expr{right}, stmtList)
+			continue
+		}
+		if param := v.paramOf(paramMap, ident); param != nil && !v.blankUses[param].IsValid() {
+			v.blankUses[param] = assign.Pos()
 		}
-		/*else {
-			if ident.Obj.Name != "_" {
-				paramMap[ident.Obj.Name] = false
+	}
+	return stmtList
+}
+
+// handleDeferredWrites marks the named results of paramMap assigned to by
+// the deferred function literal funcLit as used: it runs once the function
+// returns, so the caller sees what it assigns, as in
+// defer func() { if r := recover(); r != nil { err = ... } }().
+func (v *unusedVisitor) handleDeferredWrites(paramMap map[*ast.Ident]bool, funcLit *ast.FuncLit) {
+	ast.Inspect(funcLit.Body, func(n ast.Node) bool {
+		var targets []ast.Expr
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			targets = n.Lhs
+		case *ast.IncDecStmt:
+			targets = []ast.Expr{n.X}
+		}
+		for _, target := range targets {
+			ident, ok := target.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if param := v.paramOf(paramMap, ident); param != nil && v.namedResults[param] {
+				paramMap[param] = true
 			}
-		}*/
+		}
+		return true
+	})
+}
+
+// isBlankAssign reports whether assign only assigns to the blank
+// identifier, e.g. _ = param or _, _ = a, b.
+func isBlankAssign(assign *ast.AssignStmt) bool {
+	if assign.Tok != token.ASSIGN {
+		return false
+	}
+	for _, left := range assign.Lhs {
+		if ident, ok := left.(*ast.Ident); !ok || ident.Name != "_" {
+			return false
+		}
+	}
+	return true
+}
+
+// handleAssigned handles the targets of an assignment, increment or
+// decrement. A parameter assigned to directly is written rather than read;
+// any other target, such as p.field or m[k], reads the parameters it
+// refers to.
+func (v *unusedVisitor) handleAssigned(paramMap map[*ast.Ident]bool, exprList []ast.Expr, stmtList []ast.Stmt) []ast.Stmt {
+	for _, expr := range exprList {
+		ident, ok := expr.(*ast.Ident)
+		if !ok {
+			stmtList = v.handleExprs(paramMap, []ast.Expr{expr}, stmtList)
+			continue
+		}
+		param := v.paramOf(paramMap, ident)
+		switch {
+		case param == nil:
+		case v.writeOnly || v.namedResults[param]:
+			// writes are uses unless running with -write_only, except
+			// for named results, which are assigned to be returned
+			v.accesses[param] |= accessWrite
+		default:
+			paramMap[param] = true
+		}
+	}
+	return stmtList
+}
+
+// classify sets the reason for reporting result, a parameter of the
+// function with the given body, and reports whether it should be reported
+// at all.
+func (v *unusedVisitor) classify(result *unusedParam, used bool, body *ast.BlockStmt) bool {
+	param := result.ident
+	if shadow := v.shadowedBy(param, body); shadow != nil {
+		result.reason = ReasonShadowed
+		result.related = shadow.Pos()
+		return true
+	}
+
+	if dead := v.deadUses[param]; !used && dead.IsValid() {
+		result.reason = ReasonDeadCode
+		result.related = dead
+		return true
+	}
+	if blank := v.blankUses[param]; !used && blank.IsValid() {
+		if v.blankAssign != BlankAssignSuppress {
+			result.reason = ReasonBlankAssign
+			result.related = blank
+		} else if result.suppressedBy == "" {
+			result.suppressedBy = "_ = " + param.Name
+		}
+		return true
+	}
+	if !used && v.namedResults[param] && v.accesses[param]&(accessWrite|accessNakedReturn) == accessWrite {
+		// assigned and returned by returns with values: the assignment may
+		// still be seen by the caller, e.g. when a deferred call returns
+		return false
+	}
+	if !used {
+		result.reason = v.unusedReason(param, body)
+		return true
+	}
+	if v.overwritten && v.entryValueDead(param, body) {
+		result.reason = ReasonOverwritten
+		return true
+	}
+	return false
+}
+
+// unusedReason returns why param, which is never read, is reported. body
+// is that of the function declaring it.
+func (v *unusedVisitor) unusedReason(param *ast.Ident, body *ast.BlockStmt) Reason {
+	access := v.accesses[param]
+	switch {
+	case v.namedResults[param] && access&accessWrite == 0:
+		if onlyNakedReturns(body) {
+			return ReasonNeverAssigned
+		}
+		return ReasonUnused
+	case v.namedResults[param] && access&accessNakedReturn != 0:
+		return ReasonNakedReturn
+	case access&accessWrite == 0:
+		return ReasonUnused
+	default:
+		return ReasonWriteOnly
+	}
+}
+
+// onlyNakedReturns reports whether every return statement of the function
+// with the given body, those of function literals aside, is a naked return,
+// so that its named results are returned as they are.
+func onlyNakedReturns(body *ast.BlockStmt) bool {
+	naked := true
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			naked = naked && len(n.Results) == 0
+		}
+		return naked
+	})
+	return naked
+}
+
+// refersTo reports whether ident refers to the parameter declared by param.
+func (v *unusedVisitor) refersTo(ident, param *ast.Ident) bool {
+	decl := v.declOf(ident)
+	return decl != nil && decl == param
+}
+
+// declOf returns the identifier declaring the entity ident refers to, if it
+// is declared in the current file.
+func (v *unusedVisitor) declOf(ident *ast.Ident) *ast.Ident {
+	if v.info != nil {
+		if obj := v.info.Uses[ident]; obj != nil {
+			return v.declIdents[obj]
+		}
+		return nil
+	}
+	if obj := v.uses[ident]; obj != nil {
+		return obj.name
 	}
+	return nil
+}
 
-	// TODO - ensure this truly isn't needed - can we rely on the
-	// ident object name?
-	// if _, ok := paramMap[ident.Name]; ok {
-	// 	paramMap[ident.Name] = true
-	// }
+// paramOf returns the parameter of paramMap ident refers to, or nil.
+func (v *unusedVisitor) paramOf(paramMap map[*ast.Ident]bool, ident *ast.Ident) *ast.Ident {
+	decl := v.declOf(ident)
+	if decl == nil {
+		return nil
+	}
+	if _, ok := paramMap[decl]; !ok {
+		return nil
+	}
+	return decl
 }
 
-func (v *unusedVisitor) handleExprs(paramMap map[string]bool, exprList []ast.Expr, stmtList []ast.Stmt) []ast.Stmt {
+// declIdents maps the objects declared in f to their declaring identifiers.
+func declIdents(info *types.Info, f *ast.File) map[types.Object]*ast.Ident {
+	idents := make(map[types.Object]*ast.Ident)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			if obj := info.Defs[ident]; obj != nil {
+				idents[obj] = ident
+			}
+		}
+		return true
+	})
+	return idents
+}
+
+func (v *unusedVisitor) handleExprs(paramMap map[*ast.Ident]bool, exprList []ast.Expr, stmtList []ast.Stmt) []ast.Stmt {
 	for len(exprList) != 0 {
 		expr := exprList[0]
 		switch e := expr.(type) {
 		case *ast.Ident:
-			handleIdent(paramMap, e)
+			v.handleIdent(paramMap, e)
 
 		case *ast.BinaryExpr:
 			exprList = append(exprList, e.X) // TODO, do we need to then worry about x.left being used?
 			exprList = append(exprList, e.Y) // TODO, do we need to then worry about x.left being used?
 
 		case *ast.CallExpr:
-			exprList = append(exprList, e.Args...)
+			if v.recursive && v.currentFunc != nil {
+				exprList = append(exprList, v.passedThrough(paramMap, e)...)
+			} else {
+				exprList = append(exprList, e.Args...)
+			}
 			exprList = append(exprList, e.Fun)
 
 		case *ast.IndexExpr:
@@ -316,7 +861,7 @@ func (v *unusedVisitor) handleExprs(paramMap map[string]bool, exprList []ast.Exp
 
 		case *ast.SelectorExpr:
 			exprList = append(exprList, e.X)
-			handleIdent(paramMap, e.Sel)
+			v.handleIdent(paramMap, e.Sel)
 
 		case *ast.SliceExpr:
 			exprList = append(exprList, e.Low, e.High, e.Max, e.X)
@@ -335,10 +880,12 @@ func (v *unusedVisitor) handleExprs(paramMap map[string]bool, exprList []ast.Exp
 			// nothing to do here, no variable name
 
 		case *ast.FuncLit:
-			exprList = append(exprList, e.Type)
-			stmtList = append(stmtList, e.Body)
+			// function literals not assigned to a variable are passed as
+			// arguments, returned, launched with go/defer, etc.
+			v.handleFuncLit(paramMap, e, v.closureNames[e])
 
 		case *ast.CompositeLit:
+			exprList = append(exprList, e.Type)
 			exprList = append(exprList, e.Elts...)
 
 		case *ast.ArrayType:
@@ -348,18 +895,19 @@ func (v *unusedVisitor) handleExprs(paramMap map[string]bool, exprList []ast.Exp
 			exprList = append(exprList, e.Value)
 
 		case *ast.FuncType:
-			exprList, stmtList = handleFieldList(paramMap, e.Params, exprList, stmtList)
-			exprList, stmtList = handleFieldList(paramMap, e.Results, exprList, stmtList)
+			exprList, stmtList = v.handleFieldList(paramMap, e.Params, exprList, stmtList)
+			exprList, stmtList = v.handleFieldList(paramMap, e.Results, exprList, stmtList)
 
 		case *ast.InterfaceType:
-			exprList, stmtList = handleFieldList(paramMap, e.Methods, exprList, stmtList)
+			exprList, stmtList = v.handleFieldList(paramMap, e.Methods, exprList, stmtList)
 
 		case *ast.MapType:
 			exprList = append(exprList, e.Key, e.Value)
 
 		case *ast.StructType:
-			// TODO: no-op,  unless it contains funcs I guess? revisit this
-			// exprList, stmtList = handleFieldList(paramMap, e.Fields, exprList, stmtList)
+			// only the field types can refer to parameters (type parameters
+			// or array lengths); field names never do
+			exprList = append(exprList, fieldTypes(e.Fields)...)
 
 		case *ast.Ellipsis:
 			exprList = append(exprList, e.Elt)
@@ -380,8 +928,105 @@ func (v *unusedVisitor) handleExprs(paramMap map[string]bool, exprList []ast.Exp
 	return stmtList
 }
 
-func handleFieldList(
-	paramMap map[string]bool,
+// fieldListContains reports whether ident names one of the fields in fieldList.
+func fieldListContains(fieldList *ast.FieldList, ident *ast.Ident) bool {
+	if fieldList == nil {
+		return false
+	}
+	for _, field := range fieldList.List {
+		for _, name := range field.Names {
+			if name == ident {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recvTypeName returns the name of the receiver type of funcDecl, or an
+// empty string if funcDecl is not a method.
+func recvTypeName(funcDecl *ast.FuncDecl) string {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return ""
+	}
+	expr := funcDecl.Recv.List[0].Type
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// fieldTypes returns the types of the fields in fieldLists, leaving out
+// their names.
+func fieldTypes(fieldLists ...*ast.FieldList) []ast.Expr {
+	var exprs []ast.Expr
+	for _, fieldList := range fieldLists {
+		if fieldList == nil {
+			continue
+		}
+		for _, field := range fieldList.List {
+			exprs = append(exprs, field.Type)
+		}
+	}
+	return exprs
+}
+
+// recvTypeParams returns the type parameters declared by the receiver of
+// funcDecl, e.g. K and V for func (d dict[K, V]) Len() int.
+func recvTypeParams(funcDecl *ast.FuncDecl) []*ast.Ident {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return nil
+	}
+	var indices []ast.Expr
+	expr := funcDecl.Recv.List[0].Type
+	for indices == nil {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			indices = []ast.Expr{e.Index}
+		case *ast.IndexListExpr:
+			indices = e.Indices
+		default:
+			return nil
+		}
+	}
+
+	var typeParams []*ast.Ident
+	for _, index := range indices {
+		if ident, ok := index.(*ast.Ident); ok {
+			typeParams = append(typeParams, ident)
+		}
+	}
+	return typeParams
+}
+
+// containsIdent reports whether idents contains ident.
+func containsIdent(idents []*ast.Ident, ident *ast.Ident) bool {
+	for _, i := range idents {
+		if i == ident {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *unusedVisitor) handleFieldList(
+	paramMap map[*ast.Ident]bool,
 	fieldList *ast.FieldList,
 	exprList []ast.Expr,
 	stmtList []ast.Stmt,
@@ -392,12 +1037,12 @@ func handleFieldList(
 
 	for _, field := range fieldList.List {
 		exprList = append(exprList, field.Type)
-		handleIdents(paramMap, field.Names)
+		v.handleIdents(paramMap, field.Names)
 	}
 	return exprList, stmtList
 }
 
-func (v *unusedVisitor) handleDecls(paramMap map[string]bool, decls []ast.Decl, initialStmts []ast.Stmt) []ast.Stmt {
+func (v *unusedVisitor) handleDecls(paramMap map[*ast.Ident]bool, decls []ast.Decl, initialStmts []ast.Stmt) []ast.Stmt {
 	for _, decl := range decls {
 		switch d := decl.(type) {
 		case *ast.GenDecl:
@@ -405,23 +1050,23 @@ func (v *unusedVisitor) handleDecls(paramMap map[string]bool, decls []ast.Decl,
 				switch specType := spec.(type) {
 				case *ast.ValueSpec:
 					// TODO - I think the only specs we care about here are when we have a function declaration
-					handleIdents(paramMap, specType.Names)
+					v.handleIdents(paramMap, specType.Names)
 					initialStmts = v.handleExprs(paramMap, []ast.Expr{specType.Type}, initialStmts)
-					initialStmts = v.handleExprs(paramMap, specType.Values, initialStmts)
 
 					for index, value := range specType.Values {
 						funcLit, ok := value.(*ast.FuncLit)
-						if !ok {
+						if !ok || index >= len(specType.Names) {
+							initialStmts = v.handleExprs(paramMap, []ast.Expr{value}, initialStmts)
 							continue
 						}
 						funcName := specType.Names[index]
 						// get arguments of function, this is a candidate
 						// with potentially unused arguments
-						v.handleFuncLit(paramMap, funcLit, funcName)
+						v.handleFuncLit(paramMap, funcLit, funcName.Name)
 					}
 
 				case *ast.TypeSpec:
-					handleIdent(paramMap, specType.Name)
+					v.handleIdent(paramMap, specType.Name)
 					initialStmts = v.handleExprs(paramMap, []ast.Expr{specType.Type}, initialStmts)
 
 				case *ast.ImportSpec:
@@ -442,48 +1087,54 @@ func (v *unusedVisitor) handleDecls(paramMap map[string]bool, decls []ast.Decl,
 
 // paramMap is passed in for cases where we have an outer function with a parameter
 // that is captured by closure by the function literal
-func (v *unusedVisitor) handleFuncLit(paramMap map[string]bool, funcLit *ast.FuncLit, funcName *ast.Ident) {
-	if funcLit.Type != nil && funcLit.Type.Params != nil {
-		// declare a separate parameter map for handling
+func (v *unusedVisitor) handleFuncLit(paramMap map[*ast.Ident]bool, funcLit *ast.FuncLit, funcName string) {
+	if funcLit.Type == nil || funcLit.Type.Params == nil {
+		return
+	}
 
-		funcParamMap := make(map[string]bool)
+	// the parameters of the literal are tracked in paramMap along with
+	// those of the enclosing functions, so that its body is walked once
+	// however deeply it is nested
+	var params []*ast.Ident
+	if !v.probing {
 		for _, param := range funcLit.Type.Params.List {
 			for _, paramName := range param.Names {
 				if paramName.Name != "_" {
-					funcParamMap[paramName.Name] = false
+					params = append(params, paramName)
+					paramMap[paramName] = false
 				}
 			}
 		}
+		v.resetParams(funcLit.Type.Params)
+	}
 
-		// generate potential statements
-		v.handleStmts(funcParamMap, []ast.Stmt{funcLit.Body})
-		v.handleStmts(paramMap, []ast.Stmt{funcLit.Body})
-
-		for paramName, used := range funcParamMap {
-			if !used && paramName != "_" {
-				// TODO: this append currently causes things to appear out of order (2)
-				file := v.fileSet.File(funcLit.Pos())
-				resStr := fmt.Sprintf(
-					"%v:%v %v contains unused parameter %v\n",
-					file.Name(),
-					file.Position(funcLit.Pos()).Line,
-					funcName.Name,
-					paramName,
-				)
-				v.resultsSet[resStr] = struct{}{}
-			}
+	v.funcLitDepth++
+	v.handleStmts(paramMap, v.handleExprs(paramMap, fieldTypes(funcLit.Type.Params, funcLit.Type.Results), []ast.Stmt{funcLit.Body}))
+	v.funcLitDepth--
+
+	for _, param := range params {
+		used := paramMap[param]
+		delete(paramMap, param)
+		result := unusedParam{
+			funcName: funcName,
+			funcPos:  funcLit.Pos(),
+			ident:    param,
+			kind:     KindClosureParam,
+			severity: v.severity,
+		}
+		v.suppress(&result)
+		if !v.classify(&result, used, funcLit.Body) {
+			continue
 		}
+		v.report(result)
 	}
 }
 
 func (v *unusedVisitor) handleFuncDecl(
-	paramMap map[string]bool,
+	paramMap map[*ast.Ident]bool,
 	funcDecl *ast.FuncDecl,
 	initialStmts []ast.Stmt,
 ) []ast.Stmt {
-	if funcDecl.Body != nil {
-		initialStmts = append(initialStmts, funcDecl.Body.List...)
-	}
 	if funcDecl.Type != nil {
 		if funcDecl.Type.Params != nil {
 			for _, paramList := range funcDecl.Type.Params.List {
@@ -491,7 +1142,7 @@ func (v *unusedVisitor) handleFuncDecl(
 					if name.Name == "_" {
 						continue
 					}
-					paramMap[name.Name] = false
+					paramMap[name] = false
 				}
 			}
 		}
@@ -502,22 +1153,87 @@ func (v *unusedVisitor) handleFuncDecl(
 					if name.Name == "_" {
 						continue
 					}
-					paramMap[name.Name] = false
+					paramMap[name] = false
+					v.namedResults[name] = true
 				}
 			}
 		}
 	}
 
+	if v.includeTypeParams && funcDecl.Type != nil && funcDecl.Type.TypeParams != nil {
+		initialStmts = v.handleTypeParams(paramMap, funcDecl.Type, initialStmts)
+	}
+
 	if v.includeReceivers && funcDecl.Recv != nil {
 		for _, field := range funcDecl.Recv.List {
 			for _, name := range field.Names {
 				if name.Name == "_" {
 					continue
 				}
-				paramMap[name.Name] = false
+				paramMap[name] = false
+			}
+		}
+	}
+
+	if v.includeReceiverTypeParams {
+		if typeParams := recvTypeParams(funcDecl); len(typeParams) != 0 {
+			for _, name := range typeParams {
+				if name.Name == "_" {
+					continue
+				}
+				paramMap[name] = false
 			}
+			initialStmts = v.handleExprs(paramMap, fieldTypes(funcDecl.Type.Params, funcDecl.Type.Results), initialStmts)
 		}
 	}
 
+	if funcDecl.Body != nil {
+		v.resetParams(funcDecl.Recv, funcDecl.Type.Params, funcDecl.Type.Results)
+		initialStmts = append(initialStmts, v.liveStmts(paramMap, funcDecl.Body.List)...)
+	}
+
+	return initialStmts
+}
+
+// handleTypeParams adds the type parameters of funcType to paramMap and marks
+// those referenced by the signature or by the constraint of another type
+// parameter as used. A type parameter referenced only by its own constraint,
+// as in [T interface{ Less(T) bool }], is not considered used.
+func (v *unusedVisitor) handleTypeParams(
+	paramMap map[*ast.Ident]bool,
+	funcType *ast.FuncType,
+	initialStmts []ast.Stmt,
+) []ast.Stmt {
+	typeParams := funcType.TypeParams.List
+	for _, field := range typeParams {
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				continue
+			}
+			paramMap[name] = false
+		}
+	}
+
+	initialStmts = v.handleExprs(paramMap, fieldTypes(funcType.Params, funcType.Results), initialStmts)
+
+	for _, constrained := range typeParams {
+		others := make(map[*ast.Ident]bool)
+		for _, field := range typeParams {
+			if field == constrained {
+				continue
+			}
+			for _, name := range field.Names {
+				if _, ok := paramMap[name]; ok {
+					others[name] = false
+				}
+			}
+		}
+		initialStmts = v.handleExprs(others, []ast.Expr{constrained.Type}, initialStmts)
+		for name, used := range others {
+			if used {
+				paramMap[name] = true
+			}
+		}
+	}
 	return initialStmts
 }
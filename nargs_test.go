@@ -1,7 +1,10 @@
 package nargs
 
 import (
+	"fmt"
+	"go/token"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -47,6 +50,7 @@ func TestCheckForUnusedFunctionArgs(t *testing.T) {
 				"testdata/test.go:31 closureOne contains unused parameter v\n",
 				"testdata/test.go:39 unusedFunc contains unused parameter f\n",
 				"testdata/test.go:43 closureTwo contains unused parameter i\n",
+				"testdata/test.go:58 usedAsGlobalInterfaceMapValue[\"someFunc\"] contains unused parameter s\n",
 			},
 			wantExitWithStatus: true,
 			wantErr:            false,
@@ -65,11 +69,599 @@ func TestCheckForUnusedFunctionArgs(t *testing.T) {
 			wantResults: []string{
 				"testdata/test.go:6 funcOne contains unused parameter c\n",
 				"testdata/test.go:13 funcTwo contains unused parameter z\n",
-				"testdata/test.go:19 funcThree contains unused parameter recv\n",
-				"testdata/test.go:25 funcFour contains unused parameter namedReturn\n",
+				"testdata/test.go:19 funcThree contains unused receiver recv\n",
+				"testdata/test.go:25 funcFour contains named return namedReturn that is never assigned\n",
 				"testdata/test.go:31 closureOne contains unused parameter v\n",
 				"testdata/test.go:39 unusedFunc contains unused parameter f\n",
 				"testdata/test.go:43 closureTwo contains unused parameter i\n",
+				"testdata/test.go:58 usedAsGlobalInterfaceMapValue[\"someFunc\"] contains unused parameter s\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "File with shadowed parameters, default flags",
+			args: args{
+				cliArgs: []string{"testdata/typed.go"},
+				flags:   defaultFlags,
+			},
+			wantResults: []string{
+				"testdata/typed.go:7 shadowedByClosure contains parameter x shadowed before use on line 8\n",
+				"testdata/typed.go:15 shadowedByLocal contains parameter n shadowed before use on line 17\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "File with shadowed parameters, typed",
+			args: args{
+				cliArgs: []string{"testdata/typed.go"},
+				flags: Flags{
+					IncludeTests:  true,
+					SetExitStatus: true,
+					Typed:         true,
+				},
+			},
+			wantResults: []string{
+				"testdata/typed.go:7 shadowedByClosure contains parameter x shadowed before use on line 8\n",
+				"testdata/typed.go:15 shadowedByLocal contains parameter n shadowed before use on line 17\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "File with function literals, default flags",
+			args: args{
+				cliArgs: []string{"testdata/closures.go"},
+				flags:   defaultFlags,
+			},
+			wantResults: []string{
+				"testdata/closures.go:10 literalArguments.func1 contains unused parameter r\n",
+				"testdata/closures.go:14 literalArguments.func2 contains unused parameter j\n",
+				"testdata/closures.go:21 literalReturned.func1 contains unused parameter n\n",
+				"testdata/closures.go:28 literalsLaunched.func1 contains unused parameter id\n",
+				"testdata/closures.go:31 literalsLaunched.func2 contains unused parameter err\n",
+				"testdata/closures.go:39 literalsNested.func1.1 contains unused parameter inner\n",
+				"testdata/closures.go:60 cfg.OnClose contains unused parameter err\n",
+				"testdata/closures.go:68 filters[1] contains unused parameter v\n",
+				"testdata/closures.go:74 routes[\"GET\"][\"/\"] contains unused parameter w\n",
+				"testdata/closures.go:86 h.onEvent contains unused parameter e\n",
+				"testdata/closures.go:87 table[k] contains unused parameter x\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Write-only parameters, default flags",
+			args: args{
+				cliArgs: []string{"testdata/writeonly.go"},
+				flags:   defaultFlags,
+			},
+			wantExitWithStatus: false,
+			wantErr:            false,
+		},
+		{
+			name: "Write-only parameters, write only and named returns",
+			args: args{
+				cliArgs: []string{"testdata/writeonly.go"},
+				flags: Flags{
+					IncludeTests:        true,
+					SetExitStatus:       true,
+					IncludeNamedReturns: true,
+					WriteOnly:           true,
+				},
+			},
+			wantResults: []string{
+				"testdata/writeonly.go:5 reassigned contains write-only parameter n\n",
+				"testdata/writeonly.go:5 reassigned contains write-only parameter m\n",
+				"testdata/writeonly.go:5 reassigned contains write-only parameter total\n",
+				"testdata/writeonly.go:21 ranged contains write-only parameter k\n",
+				"testdata/writeonly.go:21 ranged contains write-only parameter v\n",
+				"testdata/writeonly.go:27 nakedReturn contains named return err only returned by naked returns\n",
+				"testdata/writeonly.go:27 nakedReturn contains named return unset that is never assigned\n",
+				"testdata/writeonly.go:34 f contains write-only parameter n\n",
+				"testdata/writeonly.go:41 writeOnlyShadowedByClosure contains write-only parameter x\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Named returns, include named returns",
+			args: args{
+				cliArgs: []string{"testdata/namedreturns.go"},
+				flags: Flags{
+					IncludeTests:        true,
+					SetExitStatus:       true,
+					IncludeNamedReturns: true,
+				},
+			},
+			wantResults: []string{
+				"testdata/namedreturns.go:6 neverAssigned contains named return count that is never assigned\n",
+				"testdata/namedreturns.go:6 neverAssigned contains named return err only returned by naked returns\n",
+				"testdata/namedreturns.go:14 nakedOnly contains named return doubled only returned by naked returns\n",
+				"testdata/namedreturns.go:48 shadowedResult contains named return value only returned by naked returns\n",
+				"testdata/namedreturns.go:48 shadowedResult contains unused named return err\n",
+				"testdata/namedreturns.go:48 shadowedResult contains named return err shadowed on line 50 before a naked return\n",
+				"testdata/namedreturns.go:72 recovered contains unused named return n\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Named returns, include named returns, typed",
+			args: args{
+				cliArgs: []string{"testdata/namedreturns.go"},
+				flags: Flags{
+					IncludeTests:        true,
+					SetExitStatus:       true,
+					IncludeNamedReturns: true,
+					Typed:               true,
+				},
+			},
+			wantResults: []string{
+				"testdata/namedreturns.go:6 neverAssigned contains named return count that is never assigned\n",
+				"testdata/namedreturns.go:6 neverAssigned contains named return err only returned by naked returns\n",
+				"testdata/namedreturns.go:14 nakedOnly contains named return doubled only returned by naked returns\n",
+				"testdata/namedreturns.go:48 shadowedResult contains named return value only returned by naked returns\n",
+				"testdata/namedreturns.go:48 shadowedResult contains unused named return err\n",
+				"testdata/namedreturns.go:48 shadowedResult contains named return err shadowed on line 50 before a naked return\n",
+				"testdata/namedreturns.go:72 recovered contains unused named return n\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Receivers, include receivers",
+			args: args{
+				cliArgs: []string{"testdata/receivers"},
+				flags: Flags{
+					IncludeTests:     true,
+					SetExitStatus:    true,
+					IncludeReceivers: true,
+				},
+			},
+			wantResults: []string{
+				"testdata/receivers/counter.go:12 Name contains unused receiver c\n",
+				"testdata/receivers/counter.go:20 Unit contains unused receiver c\n",
+				"testdata/receivers/stateless.go:7 Bold contains unused receiver f\n",
+				"testdata/receivers/stateless.go:20 Plain contains unused receiver m\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Receivers, receivers by type",
+			args: args{
+				cliArgs: []string{"testdata/receivers"},
+				flags: Flags{
+					IncludeTests:    true,
+					SetExitStatus:   true,
+					ReceiversByType: true,
+				},
+			},
+			wantResults: []string{
+				"testdata/receivers/counter.go:12 counter contains unused receiver in methods Name, Kind, Unit\n",
+				"testdata/receivers/stateless.go:7 formatter uses its receiver in none of its methods (Bold, Italic, Plain), convert them to functions\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Suppression directives, default flags",
+			args: args{
+				cliArgs: []string{"testdata/directives.go"},
+				flags:   defaultFlags,
+			},
+			wantResults: []string{
+				"testdata/directives.go:16 otherLinter contains unused parameter a\n",
+				"testdata/directives.go:19 okParams contains unused parameter c\n",
+				"testdata/directives.go:19 okParams contains unused parameter d\n",
+				"testdata/directives.go:31 register.func3 contains unused parameter w\n",
+				"testdata/directives.go:31 register.func3 contains unused parameter r\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Suppression directives, show suppressed",
+			args: args{
+				cliArgs: []string{"testdata/directives.go"},
+				flags: Flags{
+					IncludeTests:   true,
+					SetExitStatus:  true,
+					ShowSuppressed: true,
+				},
+			},
+			wantResults: []string{
+				"testdata/directives.go:6 ignored contains unused parameter a (suppressed by //nargs:ignore implements a callback signature)\n",
+				"testdata/directives.go:6 ignored contains unused parameter b (suppressed by //nargs:ignore implements a callback signature)\n",
+				"testdata/directives.go:12 nolinted contains unused parameter a (suppressed by //nolint:nargs)\n",
+				"testdata/directives.go:16 otherLinter contains unused parameter a\n",
+				"testdata/directives.go:19 okParams contains unused parameter a (suppressed by /*nargs:ok*/)\n",
+				"testdata/directives.go:19 okParams contains unused parameter b (suppressed by /*nargs:ok*/)\n",
+				"testdata/directives.go:19 okParams contains unused parameter c\n",
+				"testdata/directives.go:19 okParams contains unused parameter d\n",
+				"testdata/directives.go:24 register.func1 contains unused parameter w (suppressed by //nargs:ignore)\n",
+				"testdata/directives.go:24 register.func1 contains unused parameter r (suppressed by //nargs:ignore)\n",
+				"testdata/directives.go:27 handler contains unused parameter w (suppressed by //nolint:nargs // signature)\n",
+				"testdata/directives.go:27 handler contains unused parameter r (suppressed by //nolint:nargs // signature)\n",
+				"testdata/directives.go:31 register.func3 contains unused parameter w\n",
+				"testdata/directives.go:31 register.func3 contains unused parameter r\n",
+				"testdata/directives.go:35 nestedIgnored contains unused parameter a (suppressed by //nargs:ignore)\n",
+				"testdata/directives.go:36 nestedIgnored.func1 contains unused parameter n (suppressed by //nargs:ignore)\n",
+				"testdata/directives.go:39 okLast contains unused parameter r (suppressed by /*nargs:ok*/)\n",
+				"testdata/directives.go:46 spacedNolint contains unused parameter a (suppressed by // nolint)\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Stale directives, default flags",
+			args: args{
+				cliArgs: []string{"testdata/stale.go"},
+				flags:   defaultFlags,
+			},
+			wantResults: []string{
+				"testdata/stale.go:9 expiredIgnore contains unused parameter a (suppression by //nargs:ignore until=2020-01-01 expired)\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Stale directives, stale directives",
+			args: args{
+				cliArgs: []string{"testdata/stale.go"},
+				flags: Flags{
+					IncludeTests:    true,
+					SetExitStatus:   true,
+					StaleDirectives: true,
+				},
+			},
+			wantResults: []string{
+				"testdata/stale.go:3 directive //nargs:ignore the parameter is used now suppresses no finding\n",
+				"testdata/stale.go:9 expiredIgnore contains unused parameter a (suppression by //nargs:ignore until=2020-01-01 expired)\n",
+				"testdata/stale.go:16 directive /*nargs:ok*/ suppresses no finding\n",
+				"testdata/stale.go:25 directive //nargs:ignore suppresses no finding\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Overwritten parameters, default flags",
+			args: args{
+				cliArgs: []string{"testdata/overwritten.go"},
+				flags:   defaultFlags,
+			},
+			wantExitWithStatus: false,
+			wantErr:            false,
+		},
+		{
+			name: "Overwritten parameters, overwritten",
+			args: args{
+				cliArgs: []string{"testdata/overwritten.go"},
+				flags: Flags{
+					IncludeTests:  true,
+					SetExitStatus: true,
+					Overwritten:   true,
+				},
+			},
+			wantResults: []string{
+				"testdata/overwritten.go:8 overwritten contains overwritten parameter buf\n",
+				"testdata/overwritten.go:8 overwritten contains overwritten parameter n\n",
+				"testdata/overwritten.go:15 overwrittenOnEveryPath contains overwritten parameter s\n",
+				"testdata/overwritten.go:59 overwrittenClosure contains overwritten parameter err\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Overwritten parameters, overwritten, typed",
+			args: args{
+				cliArgs: []string{"testdata/overwritten.go"},
+				flags: Flags{
+					IncludeTests:  true,
+					SetExitStatus: true,
+					Overwritten:   true,
+					Typed:         true,
+				},
+			},
+			wantResults: []string{
+				"testdata/overwritten.go:8 overwritten contains overwritten parameter buf\n",
+				"testdata/overwritten.go:8 overwritten contains overwritten parameter n\n",
+				"testdata/overwritten.go:15 overwrittenOnEveryPath contains overwritten parameter s\n",
+				"testdata/overwritten.go:59 overwrittenClosure contains overwritten parameter err\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Overwritten parameters, overwritten, recursive",
+			args: args{
+				cliArgs: []string{"testdata/overwritten.go"},
+				flags: Flags{
+					IncludeTests:  true,
+					SetExitStatus: true,
+					Overwritten:   true,
+					Recursive:     true,
+				},
+			},
+			wantResults: []string{
+				"testdata/overwritten.go:8 overwritten contains overwritten parameter buf\n",
+				"testdata/overwritten.go:8 overwritten contains overwritten parameter n\n",
+				"testdata/overwritten.go:15 overwrittenOnEveryPath contains overwritten parameter s\n",
+				"testdata/overwritten.go:59 overwrittenClosure contains overwritten parameter err\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Overwritten parameters, overwritten, write only",
+			args: args{
+				cliArgs: []string{"testdata/overwritten.go"},
+				flags: Flags{
+					IncludeTests:  true,
+					SetExitStatus: true,
+					Overwritten:   true,
+					WriteOnly:     true,
+				},
+			},
+			wantResults: []string{
+				"testdata/overwritten.go:8 overwritten contains overwritten parameter buf\n",
+				"testdata/overwritten.go:8 overwritten contains overwritten parameter n\n",
+				"testdata/overwritten.go:15 overwrittenOnEveryPath contains overwritten parameter s\n",
+				"testdata/overwritten.go:59 overwrittenClosure contains overwritten parameter err\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Recursion, default flags",
+			args: args{
+				cliArgs: []string{"testdata/recursion"},
+				flags:   defaultFlags,
+			},
+			wantExitWithStatus: false,
+			wantErr:            false,
+		},
+		{
+			name: "Recursion, recursive",
+			args: args{
+				cliArgs: []string{"testdata/recursion"},
+				flags: Flags{
+					IncludeTests:  true,
+					SetExitStatus: true,
+					Recursive:     true,
+				},
+			},
+			wantResults: []string{
+				"testdata/recursion/recursion.go:11 walk contains recursion-only parameter depth\n",
+				"testdata/recursion/recursion.go:27 swap contains recursion-only parameter a\n",
+				"testdata/recursion/recursion.go:27 swap contains recursion-only parameter b\n",
+				"testdata/recursion/recursion.go:35 variadic contains recursion-only parameter rest\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Recursion, mutual recursion, typed",
+			args: args{
+				cliArgs: []string{"testdata/recursion"},
+				flags: Flags{
+					IncludeTests:    true,
+					SetExitStatus:   true,
+					MutualRecursion: true,
+					Typed:           true,
+				},
+			},
+			wantResults: []string{
+				"testdata/recursion/odd.go:3 isOdd contains recursion-only parameter trace\n",
+				"testdata/recursion/recursion.go:11 walk contains recursion-only parameter depth\n",
+				"testdata/recursion/recursion.go:27 swap contains recursion-only parameter a\n",
+				"testdata/recursion/recursion.go:27 swap contains recursion-only parameter b\n",
+				"testdata/recursion/recursion.go:35 variadic contains recursion-only parameter rest\n",
+				"testdata/recursion/recursion.go:47 size contains recursion-only parameter prefix\n",
+				"testdata/recursion/recursion.go:56 isEven contains recursion-only parameter trace\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Dead code, default flags",
+			args: args{
+				cliArgs: []string{"testdata/deadcode.go"},
+				flags:   defaultFlags,
+			},
+			wantExitWithStatus: false,
+			wantErr:            false,
+		},
+		{
+			name: "Dead code, dead code",
+			args: args{
+				cliArgs: []string{"testdata/deadcode.go"},
+				flags: Flags{
+					IncludeTests:  true,
+					SetExitStatus: true,
+					DeadCode:      true,
+				},
+			},
+			wantResults: []string{
+				"testdata/deadcode.go:9 usedIfFalse contains parameter a only used in dead code on line 10\n",
+				"testdata/deadcode.go:16 usedAfterReturn contains parameter a only used in dead code on line 18\n",
+				"testdata/deadcode.go:22 usedAfterPanic contains parameter a only used in dead code on line 24\n",
+				"testdata/deadcode.go:27 usedUnderDebug contains parameter a only used in dead code on line 28\n",
+				"testdata/deadcode.go:34 usedInElse contains parameter a only used in dead code on line 37\n",
+				"testdata/deadcode.go:61 deadClosure contains parameter a only used in dead code on line 64\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Dead code, dead code, typed",
+			args: args{
+				cliArgs: []string{"testdata/deadcode.go"},
+				flags: Flags{
+					IncludeTests:  true,
+					SetExitStatus: true,
+					DeadCode:      true,
+					Typed:         true,
+				},
+			},
+			wantResults: []string{
+				"testdata/deadcode.go:9 usedIfFalse contains parameter a only used in dead code on line 10\n",
+				"testdata/deadcode.go:16 usedAfterReturn contains parameter a only used in dead code on line 18\n",
+				"testdata/deadcode.go:22 usedAfterPanic contains parameter a only used in dead code on line 24\n",
+				"testdata/deadcode.go:27 usedUnderDebug contains parameter a only used in dead code on line 28\n",
+				"testdata/deadcode.go:34 usedInElse contains parameter a only used in dead code on line 37\n",
+				"testdata/deadcode.go:61 deadClosure contains parameter a only used in dead code on line 64\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Blank assignments, default flags",
+			args: args{
+				cliArgs: []string{"testdata/blank.go"},
+				flags:   defaultFlags,
+			},
+			wantExitWithStatus: false,
+			wantErr:            false,
+		},
+		{
+			name: "Blank assignments, suppress, show suppressed",
+			args: args{
+				cliArgs: []string{"testdata/blank.go"},
+				flags: Flags{
+					IncludeTests:   true,
+					SetExitStatus:  true,
+					BlankAssign:    BlankAssignSuppress,
+					ShowSuppressed: true,
+				},
+			},
+			wantResults: []string{
+				"testdata/blank.go:7 blankAssigned contains unused parameter a (suppressed by _ = a)\n",
+				"testdata/blank.go:12 blankAssignedTogether contains unused parameter a (suppressed by _ = a)\n",
+				"testdata/blank.go:12 blankAssignedTogether contains unused parameter b (suppressed by _ = b)\n",
+			},
+			// suppressed findings do not set the exit status
+			wantExitWithStatus: false,
+			wantErr:            false,
+		},
+		{
+			name: "Blank assignments, report",
+			args: args{
+				cliArgs: []string{"testdata/blank.go"},
+				flags: Flags{
+					IncludeTests:  true,
+					SetExitStatus: true,
+					BlankAssign:   BlankAssignReport,
+				},
+			},
+			wantResults: []string{
+				"testdata/blank.go:7 blankAssigned contains parameter a used only in blank assignment on line 8, rename to _ instead\n",
+				"testdata/blank.go:12 blankAssignedTogether contains parameter a used only in blank assignment on line 13, rename to _ instead\n",
+				"testdata/blank.go:12 blankAssignedTogether contains parameter b used only in blank assignment on line 13, rename to _ instead\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Blank assignments, invalid policy",
+			args: args{
+				cliArgs: []string{"testdata/blank.go"},
+				flags: Flags{
+					BlankAssign: "ignore",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Generic functions, default flags",
+			args: args{
+				cliArgs: []string{"testdata/generics.go"},
+				flags:   defaultFlags,
+			},
+			wantResults: []string{
+				"testdata/generics.go:51 converted contains unused parameter unused\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Generic functions, include type parameters",
+			args: args{
+				cliArgs: []string{"testdata/generics.go"},
+				flags: Flags{
+					IncludeTests:      true,
+					SetExitStatus:     true,
+					IncludeTypeParams: true,
+				},
+			},
+			wantResults: []string{
+				"testdata/generics.go:14 newPair contains unused type parameter K\n",
+				"testdata/generics.go:14 newPair contains unused type parameter V\n",
+				"testdata/generics.go:18 selfConstrained contains unused type parameter T\n",
+				"testdata/generics.go:39 local contains unused type parameter W\n",
+				"testdata/generics.go:51 converted contains unused parameter unused\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Generic methods, include receiver type parameters",
+			args: args{
+				cliArgs: []string{"testdata/generics.go"},
+				flags: Flags{
+					IncludeTests:              true,
+					SetExitStatus:             true,
+					IncludeReceiverTypeParams: true,
+				},
+			},
+			wantResults: []string{
+				"testdata/generics.go:51 converted contains unused parameter unused\n",
+				"testdata/generics.go:62 Len contains unused type parameter T\n",
+				"testdata/generics.go:83 Has contains unused type parameter V\n",
+				"testdata/generics.go:88 Any contains unused type parameter V\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Generic methods, include receiver type parameters, typed",
+			args: args{
+				cliArgs: []string{"testdata/generics.go"},
+				flags: Flags{
+					IncludeTests:              true,
+					SetExitStatus:             true,
+					IncludeReceiverTypeParams: true,
+					Typed:                     true,
+				},
+			},
+			wantResults: []string{
+				"testdata/generics.go:51 converted contains unused parameter unused\n",
+				"testdata/generics.go:62 Len contains unused type parameter T\n",
+				"testdata/generics.go:83 Has contains unused type parameter V\n",
+				"testdata/generics.go:88 Any contains unused type parameter V\n",
+			},
+			wantExitWithStatus: true,
+			wantErr:            false,
+		},
+		{
+			name: "Generic functions, include type parameters, typed",
+			args: args{
+				cliArgs: []string{"testdata/generics.go"},
+				flags: Flags{
+					IncludeTests:      true,
+					SetExitStatus:     true,
+					IncludeTypeParams: true,
+					Typed:             true,
+				},
+			},
+			wantResults: []string{
+				"testdata/generics.go:14 newPair contains unused type parameter K\n",
+				"testdata/generics.go:14 newPair contains unused type parameter V\n",
+				"testdata/generics.go:18 selfConstrained contains unused type parameter T\n",
+				"testdata/generics.go:39 local contains unused type parameter W\n",
+				"testdata/generics.go:51 converted contains unused parameter unused\n",
 			},
 			wantExitWithStatus: true,
 			wantErr:            false,
@@ -95,3 +687,122 @@ func TestCheckForUnusedFunctionArgs(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyze(t *testing.T) {
+	findings, err := Analyze([]string{"testdata/test.go"}, Flags{
+		IncludeNamedReturns: true,
+		IncludeReceivers:    true,
+		IncludeTests:        true,
+	})
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, fmt.Sprintf("%v:%v %v %v %v %v", f.Pos.Line, f.Pos.Column, f.Kind, f.Recv, f.Func, f.Param))
+	}
+	want := []string{
+		"6:28 param  funcOne c",
+		"13:32 param f funcTwo z",
+		"19:7 receiver f funcThree recv",
+		"25:18 named-return  funcFour namedReturn",
+		"31:21 closure-param  closureOne v",
+		"39:17 param  unusedFunc f",
+		"43:23 closure-param  closureTwo i",
+		"58:26 closure-param  usedAsGlobalInterfaceMapValue[\"someFunc\"] s",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Analyze()\ngot = %q,\nwant %q", got, want)
+	}
+}
+
+func TestFindingLess(t *testing.T) {
+	pos := func(filename string, line, column int) token.Position {
+		return token.Position{Filename: filename, Line: line, Column: column}
+	}
+	sorted := []Finding{
+		{Pos: pos("a:b/x.go", 2, 5), Func: "f", Param: "p"},
+		{Pos: pos("a:b/x.go", 10, 1), Func: "f", Param: "p"},
+		{Pos: pos("a:b/y.go", 1, 1), Func: "g", Param: "q"},
+		{Pos: pos("a:b/y.go", 1, 1), Func: "g", Param: "q", Kind: KindReceiver},
+		{Pos: pos("a:b/y.go", 1, 1), Func: "h", Param: "q", Kind: KindReceiver},
+	}
+	for i := range sorted {
+		for j := range sorted {
+			if got, want := sorted[i].Less(sorted[j]), i < j; got != want {
+				t.Errorf("Finding.Less(%v, %v) = %v, want %v", i, j, got, want)
+			}
+		}
+	}
+}
+
+func TestAnalyzeJobs(t *testing.T) {
+	flags := Flags{
+		IncludeNamedReturns: true,
+		IncludeReceivers:    true,
+		IncludeTests:        true,
+		Jobs:                1,
+	}
+	want, err := Analyze([]string{"testdata"}, flags)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	for _, jobs := range []int{0, 2, 16} {
+		flags.Jobs = jobs
+		got, err := Analyze([]string{"testdata"}, flags)
+		if err != nil {
+			t.Fatalf("Analyze() with %v jobs error = %v", jobs, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Analyze() with %v jobs\ngot = %v,\nwant %v", jobs, got, want)
+		}
+	}
+}
+
+// BenchmarkAnalyzeLargeFile analyses a file declaring many functions, whose
+// cost should grow linearly with the number of functions.
+func TestAnalyzeNestedClosures(t *testing.T) {
+	// each level of nesting used to double the time taken
+	const depth = 40
+	var src strings.Builder
+	src.WriteString("package p\n\nfunc call(f func(int)) { f(0) }\n\nfunc outer(a int) {\n")
+	for i := 0; i < depth; i++ {
+		fmt.Fprintf(&src, "call(func(p%v int) {\n", i)
+	}
+	src.WriteString("_ = a\n")
+	for i := 0; i < depth; i++ {
+		src.WriteString("})\n")
+	}
+	src.WriteString("}\n")
+
+	cfg := Config{Flags: Flags{Overwritten: true, DeadCode: true}}
+	findings, err := cfg.AnalyzeSource("p.go", []byte(src.String()))
+	if err != nil {
+		t.Fatalf("AnalyzeSource() error = %v", err)
+	}
+	if len(findings) != depth {
+		t.Errorf("AnalyzeSource() found %v unused parameters, want %v", len(findings), depth)
+	}
+}
+
+func BenchmarkAnalyzeLargeFile(b *testing.B) {
+	const funcs = 4000
+	var src strings.Builder
+	src.WriteString("package p\n\n")
+	for i := 0; i < funcs; i++ {
+		fmt.Fprintf(&src, "func f%v(a, b, c int) int { return a + b }\n", i)
+	}
+
+	cfg := Config{Flags: Flags{IncludeNamedReturns: true, IncludeReceivers: true}}
+	for i := 0; i < b.N; i++ {
+		findings, err := cfg.AnalyzeSource("p.go", []byte(src.String()))
+		if err != nil {
+			b.Fatalf("AnalyzeSource() error = %v", err)
+		}
+		if len(findings) != funcs {
+			b.Fatalf("AnalyzeSource() found %v unused parameters, want %v", len(findings), funcs)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package nargs
+
+import (
+	"go/ast"
+	"go/token"
+	"sort"
+)
+
+// method records whether a method uses its receiver, for -receivers_by_type.
+type method struct {
+	name    string
+	recv    string    // the receiver type name
+	pos     token.Pos // of the declaration
+	typePos token.Pos // of the receiver type name
+	typeEnd token.Pos
+	// unusedRecv is set if the receiver is named but reported unused.
+	unusedRecv bool
+	// noRecv is set if the receiver is unnamed or _.
+	noRecv   bool
+	severity string
+}
+
+// newMethod returns the method declared by funcDecl, whose named receiver,
+// if any, is unused if unusedRecv is set.
+func newMethod(funcDecl *ast.FuncDecl, unusedRecv bool) method {
+	field := funcDecl.Recv.List[0]
+	m := method{
+		name:       funcDecl.Name.Name,
+		recv:       recvTypeName(funcDecl),
+		pos:        funcDecl.Pos(),
+		typePos:    field.Type.Pos(),
+		typeEnd:    field.Type.End(),
+		unusedRecv: unusedRecv,
+		noRecv:     len(field.Names) == 0 || field.Names[0].Name == "_",
+	}
+	if base, ok := recvBase(field.Type).(*ast.Ident); ok {
+		m.typePos, m.typeEnd = base.Pos(), base.End()
+	}
+	return m
+}
+
+// receiverType is a type with methods not using their receiver.
+type receiverType struct {
+	name string
+	// unused lists the methods not using their receiver, by position.
+	unused []method
+	// noUse is set if none of the methods of the type uses its receiver.
+	noUse bool
+}
+
+// unusedReceiverTypes groups methods, those of a package, by receiver type
+// and returns the types with at least one method whose named receiver is
+// unused. Methods with an unnamed or blank receiver do not use it either,
+// but are only listed alongside such a method.
+func unusedReceiverTypes(fset *token.FileSet, methods []method) []receiverType {
+	sort.Slice(methods, func(i, j int) bool {
+		pi, pj := fset.Position(methods[i].pos), fset.Position(methods[j].pos)
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+
+	var recvTypes []receiverType
+	index := make(map[string]int)
+	used := make(map[string]bool)
+	reported := make(map[string]bool)
+	for _, m := range methods {
+		if m.recv == "" {
+			continue
+		}
+		if _, ok := index[m.recv]; !ok {
+			index[m.recv] = len(recvTypes)
+			recvTypes = append(recvTypes, receiverType{name: m.recv})
+		}
+		switch {
+		case m.unusedRecv:
+			reported[m.recv] = true
+		case !m.noRecv:
+			used[m.recv] = true
+			continue
+		}
+		t := &recvTypes[index[m.recv]]
+		t.unused = append(t.unused, m)
+	}
+
+	var unused []receiverType
+	for _, t := range recvTypes {
+		if reported[t.name] {
+			t.noUse = !used[t.name]
+			unused = append(unused, t)
+		}
+	}
+	return unused
+}
+
+func (t receiverType) finding(fset *token.FileSet) Finding {
+	first := t.unused[0]
+	finding := Finding{
+		Pos:      fset.Position(first.typePos),
+		End:      fset.Position(first.typeEnd),
+		FuncPos:  fset.Position(first.pos),
+		Recv:     t.name,
+		Kind:     KindReceiverType,
+		Reason:   ReasonUnused,
+		Severity: first.severity,
+	}
+	if t.noUse {
+		finding.Reason = ReasonNoReceiverUse
+	}
+	for _, m := range t.unused {
+		finding.Methods = append(finding.Methods, m.name)
+	}
+	return finding
+}
@@ -0,0 +1,210 @@
+package nargs
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// funcParam identifies a parameter by its position in the parameter list of
+// a function or method of the package being analysed.
+type funcParam struct {
+	fn    string // function name, or receiver type name and method name joined by a dot
+	index int
+}
+
+// passThrough is a parameter that is never used other than by passing it
+// unchanged to calls of functions of its own package. It is unused if each
+// of the parameters it is passed to is itself unused or only passed on.
+type passThrough struct {
+	param   funcParam
+	targets []funcParam
+	result  unusedParam
+	hidden  bool // not reported, as its kind is left out of Flags.Kinds
+}
+
+// unusedPassThroughs returns the candidates whose value is never used by
+// any function they are passed to, i.e. the largest subset of candidates
+// only passed to each other.
+func unusedPassThroughs(candidates []passThrough) []unusedParam {
+	unused := make(map[funcParam]bool, len(candidates))
+	for _, candidate := range candidates {
+		unused[candidate.param] = true
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, candidate := range candidates {
+			if !unused[candidate.param] {
+				continue
+			}
+			for _, target := range candidate.targets {
+				if !unused[target] {
+					unused[candidate.param] = false
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var results []unusedParam
+	for _, candidate := range candidates {
+		if unused[candidate.param] && !candidate.hidden {
+			results = append(results, candidate.result)
+		}
+	}
+	return results
+}
+
+// funcKey returns the name identifying funcDecl within its package.
+func funcKey(funcDecl *ast.FuncDecl) string {
+	if recv := recvTypeName(funcDecl); recv != "" {
+		return recv + "." + funcDecl.Name.Name
+	}
+	return funcDecl.Name.Name
+}
+
+// paramIndex returns the position of param in the parameter list of
+// funcDecl and whether it is the final, variadic parameter, or -1.
+func paramIndex(funcDecl *ast.FuncDecl, param *ast.Ident) (index int, variadic bool) {
+	index = 0
+	for _, field := range funcDecl.Type.Params.List {
+		for _, name := range field.Names {
+			if name == param {
+				_, variadic = field.Type.(*ast.Ellipsis)
+				return index, variadic
+			}
+			index++
+		}
+	}
+	return -1, false
+}
+
+// passedThrough handles the arguments of call, a call made by the function
+// currently being visited, that pass one of its parameters unchanged to a
+// function of the same package: itself, or with -mutual_recursion any
+// function or method it can be resolved to. Such arguments are recorded
+// rather than counted as uses; the remaining arguments are returned.
+func (v *unusedVisitor) passedThrough(paramMap map[*ast.Ident]bool, call *ast.CallExpr) []ast.Expr {
+	callee, ok := v.calleeKey(call)
+	if !ok {
+		return call.Args
+	}
+
+	var args []ast.Expr
+	for index, arg := range call.Args {
+		ident, ok := arg.(*ast.Ident)
+		if !ok {
+			args = append(args, arg)
+			continue
+		}
+
+		param := v.paramOf(paramMap, ident)
+		if param == nil {
+			args = append(args, arg)
+			continue
+		}
+		own, variadic := paramIndex(v.currentFunc, param)
+		if own < 0 || (variadic && !call.Ellipsis.IsValid()) {
+			args = append(args, arg)
+			continue
+		}
+		if v.passedTo[param] == nil {
+			v.passedTo[param] = make(map[funcParam]bool)
+		}
+		v.passedTo[param][funcParam{fn: callee, index: index}] = true
+	}
+	return args
+}
+
+// calleeKey returns the key of the function of the package being analysed
+// called by call, if it can be determined. Without -mutual_recursion, only
+// calls of the current function itself are considered.
+func (v *unusedVisitor) calleeKey(call *ast.CallExpr) (string, bool) {
+	fun := call.Fun
+	for {
+		switch e := fun.(type) {
+		case *ast.ParenExpr:
+			fun = e.X
+			continue
+		case *ast.IndexExpr:
+			fun = e.X
+			continue
+		case *ast.IndexListExpr:
+			fun = e.X
+			continue
+		}
+		break
+	}
+
+	var key string
+	if v.info != nil {
+		key = v.typedCalleeKey(fun)
+	} else {
+		key = v.untypedCalleeKey(fun)
+	}
+	if key == "" || (!v.mutualRecursion && key != funcKey(v.currentFunc)) {
+		return "", false
+	}
+	return key, true
+}
+
+// typedCalleeKey returns the key of the function or method of the current
+// package that fun denotes, or an empty string.
+func (v *unusedVisitor) typedCalleeKey(fun ast.Expr) string {
+	var ident *ast.Ident
+	switch e := fun.(type) {
+	case *ast.Ident:
+		ident = e
+	case *ast.SelectorExpr:
+		ident = e.Sel
+	default:
+		return ""
+	}
+
+	fn, ok := v.info.Uses[ident].(*types.Func)
+	current, _ := v.info.Defs[v.currentFunc.Name].(*types.Func)
+	if !ok || current == nil || fn.Pkg() != current.Pkg() {
+		return ""
+	}
+	fn = fn.Origin()
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return fn.Name()
+	}
+	recv := sig.Recv().Type()
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = ptr.Elem()
+	}
+	named, ok := recv.(*types.Named)
+	if !ok {
+		return ""
+	}
+	return named.Obj().Name() + "." + fn.Name()
+}
+
+// untypedCalleeKey returns the key of the function fun denotes if it names
+// a package-level function, or a method called on the receiver of the
+// current method, or an empty string.
+func (v *unusedVisitor) untypedCalleeKey(fun ast.Expr) string {
+	switch e := fun.(type) {
+	case *ast.Ident:
+		if obj := v.uses[e]; obj != nil && obj.kind != objFunc {
+			// a local variable, type, etc.
+			return ""
+		}
+		return e.Name
+
+	case *ast.SelectorExpr:
+		x, ok := e.X.(*ast.Ident)
+		recv := v.currentFunc.Recv
+		if !ok || recv == nil || len(recv.List) == 0 || len(recv.List[0].Names) == 0 {
+			return ""
+		}
+		if obj := v.uses[x]; obj == nil || obj != v.defs[recv.List[0].Names[0]] {
+			return ""
+		}
+		return recvTypeName(v.currentFunc) + "." + e.Sel.Name
+	}
+	return ""
+}
@@ -0,0 +1,440 @@
+package nargs
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// objKind is the kind of entity an object is.
+type objKind int
+
+const (
+	objVar objKind = iota
+	objConst
+	objType
+	objFunc
+)
+
+// object is an entity declared in a file, as resolved by resolveFile.
+type object struct {
+	name *ast.Ident // the declaring identifier
+	kind objKind
+	// spec declares the constant or variable, if it is declared by a const
+	// or var declaration.
+	spec *ast.ValueSpec
+}
+
+// scope is a block, mapping the names declared in it to their objects.
+type scope struct {
+	outer   *scope
+	objects map[string]*object
+}
+
+// lookup returns the object name refers to in s, or nil if it is not
+// declared in s or any enclosing block.
+func (s *scope) lookup(name string) *object {
+	for ; s != nil; s = s.outer {
+		if obj, ok := s.objects[name]; ok {
+			return obj
+		}
+	}
+	return nil
+}
+
+// resolver resolves identifiers syntactically, block by block, recording
+// the objects declared and used in the manner of types.Info's Defs and
+// Uses.
+type resolver struct {
+	scope *scope
+	defs  map[*ast.Ident]*object
+	uses  map[*ast.Ident]*object
+}
+
+// resolveFile resolves the identifiers of f that denote entities declared
+// in f, returning the object declared by each declaring identifier and the
+// object referred to by each other identifier. Identifiers declared in
+// other files, package names and predeclared identifiers are left
+// unresolved, as are selectors, struct fields, interface methods and
+// labels. Keys of composite literals are resolved if their name is in
+// scope, as without types a map literal cannot be told from a struct one.
+func resolveFile(f *ast.File) (defs, uses map[*ast.Ident]*object) {
+	r := &resolver{
+		defs: make(map[*ast.Ident]*object),
+		uses: make(map[*ast.Ident]*object),
+	}
+	r.openScope()
+	// package-level declarations are in scope throughout the file
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Name.Name != "init" {
+				r.declare(d.Name, objFunc, nil)
+			}
+		case *ast.GenDecl:
+			r.declareSpecs(d)
+		}
+	}
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			r.walkFuncDecl(d)
+		case *ast.GenDecl:
+			r.walkSpecs(d, false)
+		}
+	}
+	r.closeScope()
+	return r.defs, r.uses
+}
+
+func (r *resolver) openScope() {
+	r.scope = &scope{outer: r.scope, objects: make(map[string]*object)}
+}
+
+func (r *resolver) closeScope() {
+	r.scope = r.scope.outer
+}
+
+// declare declares ident in the current block.
+func (r *resolver) declare(ident *ast.Ident, kind objKind, spec *ast.ValueSpec) {
+	if ident.Name == "_" {
+		return
+	}
+	obj := &object{name: ident, kind: kind, spec: spec}
+	r.scope.objects[ident.Name] = obj
+	r.defs[ident] = obj
+}
+
+// resolve records the object ident refers to, if any.
+func (r *resolver) resolve(ident *ast.Ident) {
+	if obj := r.scope.lookup(ident.Name); obj != nil {
+		r.uses[ident] = obj
+	}
+}
+
+func (r *resolver) walk(node ast.Node) {
+	if node != nil {
+		ast.Walk(r, node)
+	}
+}
+
+func (r *resolver) walkExprs(exprs []ast.Expr) {
+	for _, expr := range exprs {
+		r.walk(expr)
+	}
+}
+
+func (r *resolver) walkStmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		r.walk(stmt)
+	}
+}
+
+// walkFieldTypes resolves the types of the fields in list, leaving out their
+// names.
+func (r *resolver) walkFieldTypes(list *ast.FieldList) {
+	if list == nil {
+		return
+	}
+	for _, field := range list.List {
+		r.walk(field.Type)
+	}
+}
+
+// declareFields declares the names of the fields in list as variables.
+func (r *resolver) declareFields(list *ast.FieldList) {
+	if list == nil {
+		return
+	}
+	for _, field := range list.List {
+		for _, name := range field.Names {
+			r.declare(name, objVar, nil)
+		}
+	}
+}
+
+// declareTypeParams declares the type parameters in list, then resolves
+// their constraints, which may refer to any of them.
+func (r *resolver) declareTypeParams(list *ast.FieldList) {
+	if list == nil {
+		return
+	}
+	for _, field := range list.List {
+		for _, name := range field.Names {
+			r.declare(name, objType, nil)
+		}
+	}
+	r.walkFieldTypes(list)
+}
+
+// declareSignature declares the type parameters, parameters and results of
+// typ in the current block, which must be the function's, after resolving
+// their types.
+func (r *resolver) declareSignature(typ *ast.FuncType) {
+	r.declareTypeParams(typ.TypeParams)
+	r.walkFieldTypes(typ.Params)
+	r.walkFieldTypes(typ.Results)
+	r.declareFields(typ.Params)
+	r.declareFields(typ.Results)
+}
+
+func (r *resolver) walkFuncDecl(d *ast.FuncDecl) {
+	r.openScope()
+	if d.Recv != nil {
+		for _, name := range recvTypeParams(d) {
+			r.declare(name, objType, nil)
+		}
+		for _, field := range d.Recv.List {
+			r.walk(recvBase(field.Type))
+		}
+		r.declareFields(d.Recv)
+	}
+	r.declareSignature(d.Type)
+	if d.Body != nil {
+		// the body is the function's block, not one nested in it
+		r.walkStmts(d.Body.List)
+	}
+	r.closeScope()
+}
+
+// recvBase returns the receiver type expr without its pointer indirection
+// and type parameters, which are declared rather than used by the receiver.
+func recvBase(expr ast.Expr) ast.Expr {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		default:
+			return expr
+		}
+	}
+}
+
+// declareSpecs declares the constants, types and variables of the
+// package-level declaration d.
+func (r *resolver) declareSpecs(d *ast.GenDecl) {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.ValueSpec:
+			kind := objVar
+			if d.Tok == token.CONST {
+				kind = objConst
+			}
+			for _, name := range s.Names {
+				r.declare(name, kind, s)
+			}
+		case *ast.TypeSpec:
+			r.declare(s.Name, objType, nil)
+		}
+	}
+}
+
+// walkSpecs resolves the types and values of the declaration d, declaring
+// its names in the current block if declare is set. Constants and variables
+// are in scope from the end of their spec, types from their name on.
+func (r *resolver) walkSpecs(d *ast.GenDecl, declare bool) {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.ValueSpec:
+			r.walk(s.Type)
+			r.walkExprs(s.Values)
+			if !declare {
+				continue
+			}
+			kind := objVar
+			if d.Tok == token.CONST {
+				kind = objConst
+			}
+			for _, name := range s.Names {
+				r.declare(name, kind, s)
+			}
+		case *ast.TypeSpec:
+			if declare {
+				r.declare(s.Name, objType, nil)
+			}
+			r.openScope()
+			r.declareTypeParams(s.TypeParams)
+			r.walk(s.Type)
+			r.closeScope()
+		}
+	}
+}
+
+// Visit implements the ast.Visitor Visit method.
+func (r *resolver) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	case *ast.Ident:
+		r.resolve(n)
+
+	case *ast.SelectorExpr:
+		r.walk(n.X)
+		return nil
+
+	case *ast.StructType:
+		r.walkFieldTypes(n.Fields)
+		return nil
+
+	case *ast.InterfaceType:
+		r.walkFieldTypes(n.Methods)
+		return nil
+
+	case *ast.FuncType:
+		// a function type outside of a declaration or literal: its
+		// parameter names are only documentation
+		r.walkFieldTypes(n.TypeParams)
+		r.walkFieldTypes(n.Params)
+		r.walkFieldTypes(n.Results)
+		return nil
+
+	case *ast.FuncLit:
+		r.openScope()
+		r.declareSignature(n.Type)
+		r.walkStmts(n.Body.List)
+		r.closeScope()
+		return nil
+
+	case *ast.CompositeLit:
+		r.walk(n.Type)
+		for _, elt := range n.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				r.walk(elt)
+				continue
+			}
+			if key, ok := kv.Key.(*ast.Ident); ok {
+				r.resolve(key)
+			} else {
+				r.walk(kv.Key)
+			}
+			r.walk(kv.Value)
+		}
+		return nil
+
+	case *ast.GenDecl:
+		r.walkSpecs(n, true)
+		return nil
+
+	case *ast.AssignStmt:
+		r.walkExprs(n.Rhs)
+		if n.Tok != token.DEFINE {
+			r.walkExprs(n.Lhs)
+			return nil
+		}
+		for _, lhs := range n.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if obj, ok := r.scope.objects[ident.Name]; ok {
+				// redeclared, i.e. assigned to
+				r.uses[ident] = obj
+			} else {
+				r.declare(ident, objVar, nil)
+			}
+		}
+		return nil
+
+	case *ast.LabeledStmt:
+		r.walk(n.Stmt)
+		return nil
+
+	case *ast.BranchStmt:
+		return nil
+
+	case *ast.BlockStmt:
+		r.openScope()
+		r.walkStmts(n.List)
+		r.closeScope()
+		return nil
+
+	case *ast.IfStmt:
+		r.openScope()
+		r.walk(n.Init)
+		r.walk(n.Cond)
+		r.walk(n.Body)
+		r.walk(n.Else)
+		r.closeScope()
+		return nil
+
+	case *ast.ForStmt:
+		r.openScope()
+		r.walk(n.Init)
+		r.walk(n.Cond)
+		r.walk(n.Post)
+		r.walk(n.Body)
+		r.closeScope()
+		return nil
+
+	case *ast.RangeStmt:
+		r.walk(n.X)
+		r.openScope()
+		if n.Tok == token.DEFINE {
+			for _, expr := range []ast.Expr{n.Key, n.Value} {
+				if ident, ok := expr.(*ast.Ident); ok {
+					r.declare(ident, objVar, nil)
+				}
+			}
+		} else {
+			r.walk(n.Key)
+			r.walk(n.Value)
+		}
+		r.walk(n.Body)
+		r.closeScope()
+		return nil
+
+	case *ast.SwitchStmt:
+		r.openScope()
+		r.walk(n.Init)
+		r.walk(n.Tag)
+		r.walk(n.Body)
+		r.closeScope()
+		return nil
+
+	case *ast.TypeSwitchStmt:
+		r.openScope()
+		r.walk(n.Init)
+		// the symbolic variable of x := y.(type) is declared in every clause
+		var symbolic *object
+		if assign, ok := n.Assign.(*ast.AssignStmt); ok && len(assign.Lhs) == 1 {
+			r.walkExprs(assign.Rhs)
+			if ident, ok := assign.Lhs[0].(*ast.Ident); ok && ident.Name != "_" {
+				symbolic = &object{name: ident, kind: objVar}
+				r.defs[ident] = symbolic
+			}
+		} else {
+			r.walk(n.Assign)
+		}
+		for _, stmt := range n.Body.List {
+			clause := stmt.(*ast.CaseClause)
+			r.openScope()
+			r.walkExprs(clause.List)
+			if symbolic != nil {
+				r.scope.objects[symbolic.name.Name] = symbolic
+			}
+			r.walkStmts(clause.Body)
+			r.closeScope()
+		}
+		r.closeScope()
+		return nil
+
+	case *ast.CaseClause:
+		r.openScope()
+		r.walkExprs(n.List)
+		r.walkStmts(n.Body)
+		r.closeScope()
+		return nil
+
+	case *ast.CommClause:
+		r.openScope()
+		r.walk(n.Comm)
+		r.walkStmts(n.Body)
+		r.closeScope()
+		return nil
+	}
+	return r
+}
@@ -0,0 +1,111 @@
+package nargs
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestResolveFile(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// want lists each use of a local object as "name line:col -> line:col"
+		want []string
+	}{
+		{
+			name: "closure parameter shadows parameter",
+			src: `package p
+func f(x int) {
+	g := func(x int) int { return x }
+	g(x)
+}`,
+			want: []string{
+				"g 4:2 -> 3:2",
+				"x 3:32 -> 3:12",
+				"x 4:4 -> 2:8",
+			},
+		},
+		{
+			name: "block scopes",
+			src: `package p
+func f(n int) int {
+	if n := n + 1; n > 0 {
+		return n
+	}
+	for i, n := range []int{n} {
+		_ = i + n
+	}
+	return n
+}`,
+			want: []string{
+				"i 7:7 -> 6:6",
+				"n 3:10 -> 2:8",
+				"n 3:17 -> 3:5",
+				"n 4:10 -> 3:5",
+				"n 6:26 -> 2:8",
+				"n 7:11 -> 6:9",
+				"n 9:9 -> 2:8",
+			},
+		},
+		{
+			name: "redeclaration, type switch and labels",
+			src: `package p
+func f(err error, v interface{}) error {
+	n, err := 1, err
+	switch v := v.(type) {
+	case int:
+		n = v
+	}
+err:
+	for {
+		break err
+	}
+	return err
+}`,
+			want: []string{
+				"err 12:9 -> 2:8",
+				"err 3:15 -> 2:8",
+				"err 3:5 -> 2:8",
+				"n 6:3 -> 3:2",
+				"v 4:14 -> 2:19",
+				"v 6:7 -> 4:9",
+			},
+		},
+		{
+			name: "generic receiver and fields",
+			src: `package p
+type List[T any] struct{ T T }
+func (l *List[T]) Set(T T) { l.T = T }`,
+			want: []string{
+				"List 3:10 -> 2:6",
+				"T 2:28 -> 2:11",
+				"T 3:25 -> 3:15",
+				"T 3:36 -> 3:23",
+				"l 3:30 -> 3:7",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			f, err := parser.ParseFile(fset, "p.go", tt.src, parser.SkipObjectResolution)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, uses := resolveFile(f)
+			var got []string
+			for ident, obj := range uses {
+				use, decl := fset.Position(ident.Pos()), fset.Position(obj.name.Pos())
+				got = append(got, fmt.Sprintf("%v %v:%v -> %v:%v", ident.Name, use.Line, use.Column, decl.Line, decl.Column))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveFile() uses = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package nargs
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Config describes the input and settings of a Run.
+type Config struct {
+	// Args lists the files, directories and packages to analyse, in any
+	// form accepted by the nargs command. When empty, the package in the
+	// current directory is analysed.
+	Args  []string
+	Flags Flags
+	// Overlay maps file names to contents that take precedence over the
+	// files on disk, e.g. unsaved editor buffers. Files in Overlay need not
+	// exist on disk; they are part of the package of their directory.
+	Overlay map[string][]byte
+	// ConfigFiles enables the configuration files (see ConfigFile) of the
+	// working directory and of the directories of the files analysed, whose
+	// settings override those of Flags. The settings deciding what is loaded,
+	// e.g. tests and typed, and how, i.e. mod, jobs and set_exit_status, are
+	// those applying to the working directory.
+	ConfigFiles bool
+	// Explicit lists the settings of Flags, by configuration file key, e.g.
+	// "receivers", that configuration files do not override, typically those
+	// set on the command line.
+	Explicit []string
+}
+
+// Run parses the files/packages described by cfg and walks their AST
+// searching for unused function parameters, calling emit with the findings
+// of each file as soon as that file has been analysed. Findings of a single
+// file are emitted in the order defined by Finding.Less; files complete in
+// no particular order. emit is never called concurrently.
+//
+// Run stops promptly once ctx is done: no further file is started and the
+// context's error is returned. Files that were not analysed, due to
+// cancellation or to an error, are returned by name: all of them if ctx is
+// done before the input has been loaded.
+func Run(ctx context.Context, cfg Config, emit func(Finding)) (unanalysed []string, _ error) {
+	var loader *configLoader
+	if cfg.ConfigFiles {
+		loader = newConfigLoader(cfg.Flags, cfg.Explicit)
+		flags, err := loader.dirFlags(pwd)
+		if err != nil {
+			return nil, fmt.Errorf("could not load configuration, %v", err)
+		}
+		cfg.Flags = flags
+	}
+	if err := cfg.Flags.validate(); err != nil {
+		return nil, err
+	}
+	cfg.Overlay = absOverlay(cfg.Overlay)
+	fset := token.NewFileSet()
+	files, err := parseInput(ctx, cfg, fset)
+	if err != nil {
+		if ctx.Err() != nil {
+			// none of the input was analysed; name it all, listing it
+			// without the cancelled ctx if loading was interrupted
+			unanalysed, _ := inputFiles(context.WithoutCancel(ctx), cfg)
+			return unanalysed, ctx.Err()
+		}
+		return nil, fmt.Errorf("could not parse input, %v", err)
+	}
+	if loader != nil {
+		if files, err = loader.configure(files); err != nil {
+			return nil, fmt.Errorf("could not load configuration, %v", err)
+		}
+	}
+
+	return analyzeFiles(ctx, fset, files, cfg.Flags, emit)
+}
+
+// Analyze runs cfg to completion and returns the findings, sorted as
+// defined by Finding.Less.
+func (cfg Config) Analyze() ([]Finding, error) {
+	var findings []Finding
+	_, err := Run(context.Background(), cfg, func(finding Finding) {
+		findings = append(findings, finding)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Less(findings[j])
+	})
+	return findings, nil
+}
+
+// Check runs cfg like Analyze, rendering each finding with Finding.String
+// followed by a newline. Suppressed findings and warnings do not set
+// exitWithStatus.
+func (cfg Config) Check() (results []string, exitWithStatus bool, _ error) {
+	flags, err := cfg.EffectiveFlags()
+	if err != nil {
+		return nil, false, err
+	}
+	findings, err := cfg.Analyze()
+	if err != nil {
+		return nil, false, err
+	}
+
+	reported := 0
+	for _, finding := range findings {
+		results = append(results, finding.String()+"\n")
+		if finding.SuppressedBy == "" && !finding.Warning() {
+			reported++
+		}
+	}
+
+	return results, reported > 0 && flags.SetExitStatus, nil
+}
+
+// EffectiveFlags returns cfg.Flags with the settings of the configuration
+// files applying to the working directory, with cfg.ConfigFiles, but
+// without their overrides.
+func (cfg Config) EffectiveFlags() (Flags, error) {
+	if !cfg.ConfigFiles {
+		return cfg.Flags, nil
+	}
+	flags, err := newConfigLoader(cfg.Flags, cfg.Explicit).dirFlags(pwd)
+	if err != nil {
+		return flags, fmt.Errorf("could not load configuration, %v", err)
+	}
+	return flags, nil
+}
+
+// AnalyzeSource searches src, the contents of filename, for unused function
+// parameters without reading any file or loading its package. Identifiers
+// are always resolved syntactically, as if cfg.Flags.Typed were false;
+// cfg.Args and cfg.Overlay are ignored.
+func (cfg Config) AnalyzeSource(filename string, src []byte) ([]Finding, error) {
+	if err := cfg.Flags.validate(); err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	flags := cfg.Flags
+	flags.Typed = false
+	retVis := newUnusedVisitor(fset, flags)
+	findings, facts, err := retVis.analyzeFile(&sourceFile{filename: filename, src: src})
+	if err != nil {
+		return nil, fmt.Errorf("could not parse input, %v", err)
+	}
+	findings = append(findings, facts.findings(fset)...)
+
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Less(findings[j])
+	})
+	return findings, nil
+}
+
+// fileResult holds the outcome of analysing files[index].
+type fileResult struct {
+	index    int
+	findings []Finding
+	facts    packageFacts
+	err      error
+}
+
+// packageFacts holds what the files of a package contribute to the findings
+// that depend on the package as a whole.
+type packageFacts struct {
+	passThroughs []passThrough // with -mutual_recursion
+	methods      []method      // with -receivers_by_type
+}
+
+func (facts *packageFacts) add(other packageFacts) {
+	facts.passThroughs = append(facts.passThroughs, other.passThroughs...)
+	facts.methods = append(facts.methods, other.methods...)
+}
+
+func (facts packageFacts) empty() bool {
+	return len(facts.passThroughs) == 0 && len(facts.methods) == 0
+}
+
+// findings returns the findings of the package facts was gathered from.
+func (facts packageFacts) findings(fset *token.FileSet) []Finding {
+	var findings []Finding
+	for _, result := range unusedPassThroughs(facts.passThroughs) {
+		findings = append(findings, result.finding(fset))
+	}
+	for _, recvType := range unusedReceiverTypes(fset, facts.methods) {
+		findings = append(findings, recvType.finding(fset))
+	}
+	return findings
+}
+
+// analyzeFiles parses (where needed) and walks files using a pool of
+// workers, each with its own visitor or a visitor per file for files with
+// flags of their own, passing the findings of each file to
+// emit as it completes. Findings that depend on the other files of their
+// package (-mutual_recursion, -receivers_by_type) are emitted once every file
+// has been analysed, and only if none failed.
+func analyzeFiles(
+	ctx context.Context,
+	fset *token.FileSet,
+	files []*sourceFile,
+	flags Flags,
+	emit func(Finding),
+) ([]string, error) {
+	workers := flags.Jobs
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	parentCtx := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indexes := make(chan int)
+	go func() {
+		defer close(indexes)
+		for index := range files {
+			select {
+			case indexes <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make(chan fileResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			retVis := newUnusedVisitor(fset, flags)
+			for index := range indexes {
+				if ctx.Err() != nil {
+					continue
+				}
+				fileVis := retVis
+				if files[index].flags != nil {
+					fileVis = newUnusedVisitor(fset, *files[index].flags)
+				}
+				findings, facts, err := fileVis.analyzeFile(files[index])
+				results <- fileResult{index: index, findings: findings, facts: facts, err: err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	analysed := make([]bool, len(files))
+	facts := make(map[string]*packageFacts)
+	var firstErr error
+	for result := range results {
+		if result.err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("could not parse input, %v", result.err)
+				cancel()
+			}
+			continue
+		}
+
+		analysed[result.index] = true
+		if !result.facts.empty() {
+			pkg := files[result.index].packageKey()
+			if facts[pkg] == nil {
+				facts[pkg] = new(packageFacts)
+			}
+			facts[pkg].add(result.facts)
+		}
+		emitSorted(result.findings, emit)
+	}
+
+	var unanalysed []string
+	for index, ok := range analysed {
+		if !ok {
+			unanalysed = append(unanalysed, files[index].filename)
+		}
+	}
+	if firstErr == nil && len(unanalysed) != 0 {
+		firstErr = parentCtx.Err()
+	}
+	if len(unanalysed) == 0 {
+		pkgs := make([]string, 0, len(facts))
+		for pkg := range facts {
+			pkgs = append(pkgs, pkg)
+		}
+		sort.Strings(pkgs)
+		for _, pkg := range pkgs {
+			emitSorted(facts[pkg].findings(fset), emit)
+		}
+	}
+	return unanalysed, firstErr
+}
+
+// emitSorted passes findings to emit in the order defined by Finding.Less.
+func emitSorted(findings []Finding, emit func(Finding)) {
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Less(findings[j])
+	})
+	for _, finding := range findings {
+		emit(finding)
+	}
+}
@@ -0,0 +1,146 @@
+package nargs
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	cfg := Config{
+		Args:  []string{"testdata/test.go", "testdata/typed.go"},
+		Flags: Flags{IncludeTests: true},
+	}
+
+	var got []Finding
+	unanalysed, err := Run(context.Background(), cfg, func(finding Finding) {
+		got = append(got, finding)
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(unanalysed) != 0 {
+		t.Errorf("Run() unanalysed = %v, want none", unanalysed)
+	}
+
+	want, err := Analyze(cfg.Args, cfg.Flags)
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	sort.Slice(got, func(i, j int) bool {
+		return got[i].Less(got[j])
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Run()\ngot = %v,\nwant %v", got, want)
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	cfg := Config{
+		Args:  []string{"testdata/test.go", "testdata/typed.go"},
+		Flags: Flags{IncludeTests: true},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	unanalysed, err := Run(ctx, cfg, func(finding Finding) {
+		t.Errorf("Run() emitted %v after cancellation", finding)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+	if want := []string{"testdata/test.go", "testdata/typed.go"}; !reflect.DeepEqual(unanalysed, want) {
+		t.Errorf("Run() unanalysed = %v, want %v", unanalysed, want)
+	}
+}
+
+func TestRunCancelledLoading(t *testing.T) {
+	cfg := Config{
+		Args:  []string{"./testdata/pkg"},
+		Flags: Flags{IncludeTests: true, Typed: true},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	unanalysed, err := Run(ctx, cfg, func(finding Finding) {
+		t.Errorf("Run() emitted %v after cancellation", finding)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want %v", err, context.Canceled)
+	}
+	if want := []string{"testdata/pkg/pkg.go", "testdata/pkg/pkg_test.go"}; !reflect.DeepEqual(unanalysed, want) {
+		t.Errorf("Run() unanalysed = %v, want %v", unanalysed, want)
+	}
+}
+
+func TestRunOverlay(t *testing.T) {
+	overlay := map[string][]byte{
+		"testdata/pkg/pkg.go":     []byte("package pkg\n\nfunc Sum(a, b, c int) int {\n\treturn a + b + c\n}\n\nfunc edited(a, b int) int {\n\treturn a\n}\n"),
+		"testdata/pkg/unsaved.go": []byte("package pkg\n\nfunc unsaved(p int) {}\n"),
+	}
+	tests := []struct {
+		name  string
+		args  []string
+		typed bool
+		want  []string
+	}{
+		{
+			name: "Files",
+			args: []string{"testdata/pkg/pkg.go", "testdata/pkg/unsaved.go"},
+			want: []string{"edited b", "unsaved p"},
+		},
+		{
+			name: "Package",
+			args: []string{"./testdata/pkg"},
+			want: []string{"edited b", "unsaved p"},
+		},
+		{
+			name:  "Package, typed",
+			args:  []string{"./testdata/pkg"},
+			typed: true,
+			want:  []string{"edited b", "unsaved p"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{
+				Args:    tt.args,
+				Flags:   Flags{IncludeTests: true, Typed: tt.typed},
+				Overlay: overlay,
+			}
+			var got []string
+			_, err := Run(context.Background(), cfg, func(finding Finding) {
+				got = append(got, finding.Func+" "+finding.Param)
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Run()\ngot = %v,\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSource(t *testing.T) {
+	src := []byte("package p\n\nfunc f(a, b int) int {\n\tg := func(c int) {}\n\tg(a)\n\treturn a\n}\n")
+	got, err := Config{}.AnalyzeSource("p.go", src)
+	if err != nil {
+		t.Fatalf("AnalyzeSource() error = %v", err)
+	}
+
+	var gotStrings []string
+	for _, finding := range got {
+		gotStrings = append(gotStrings, finding.String())
+	}
+	want := []string{
+		"p.go:3 f contains unused parameter b",
+		"p.go:4 g contains unused parameter c",
+	}
+	if !reflect.DeepEqual(gotStrings, want) {
+		t.Errorf("AnalyzeSource()\ngot = %v,\nwant %v", gotStrings, want)
+	}
+}
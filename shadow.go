@@ -0,0 +1,115 @@
+package nargs
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// shadowedBy returns the declaration of the variable shadowing param, a
+// parameter of the function with the given body, if param is never
+// referenced but a variable of the same name is declared in body, which is
+// then most likely what the uses of that name were meant to refer to.
+func (v *unusedVisitor) shadowedBy(param *ast.Ident, body *ast.BlockStmt) *ast.Ident {
+	if body == nil || !v.isVar(param) || v.namedResults[param] {
+		return nil
+	}
+
+	var shadow *ast.Ident
+	referenced := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok || ident == param || ident.Name != param.Name {
+			return !referenced
+		}
+		switch {
+		case v.refersTo(ident, param):
+			referenced = true
+		case shadow == nil && v.declaresVar(ident):
+			shadow = ident
+		}
+		return !referenced
+	})
+
+	if referenced {
+		return nil
+	}
+	return shadow
+}
+
+// declaresVar reports whether ident is the name of a variable in its
+// declaration.
+func (v *unusedVisitor) declaresVar(ident *ast.Ident) bool {
+	if v.info != nil {
+		_, ok := v.info.Defs[ident].(*types.Var)
+		return ok
+	}
+	obj := v.defs[ident]
+	return obj != nil && obj.kind == objVar
+}
+
+// shadowingResult returns the declarations, in the function with the given
+// body, of variables shadowing the named result param in a scope followed by
+// a naked return, which returns param unchanged rather than the shadowing
+// variable. Naked returns cannot appear within the shadowing scope itself,
+// the compiler rejects them there; function literals, whose naked returns
+// return their own results, are skipped.
+func (v *unusedVisitor) shadowingResult(param *ast.Ident, body *ast.BlockStmt) []*ast.Ident {
+	if body == nil || !v.namedResults[param] {
+		return nil
+	}
+
+	type declaration struct {
+		ident *ast.Ident
+		scope ast.Node
+	}
+	var decls []declaration
+	var nakedReturns []*ast.ReturnStmt
+	var scopes []ast.Node
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			scopes = scopes[:len(scopes)-1]
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(n.Results) == 0 {
+				nakedReturns = append(nakedReturns, n)
+			}
+		case *ast.Ident:
+			if n != param && n.Name == param.Name && len(scopes) > 1 && v.declaresVar(n) {
+				decls = append(decls, declaration{ident: n, scope: scopes[len(scopes)-1]})
+			}
+		}
+		if opensScope(n) {
+			scopes = append(scopes, n)
+		} else {
+			// keep the stack balanced with the nil visit that follows
+			scopes = append(scopes, scopes[len(scopes)-1])
+		}
+		return true
+	})
+
+	var shadows []*ast.Ident
+	for _, decl := range decls {
+		for _, ret := range nakedReturns {
+			if ret.Pos() > decl.scope.End() {
+				shadows = append(shadows, decl.ident)
+				break
+			}
+		}
+	}
+	return shadows
+}
+
+// opensScope reports whether n is a statement or clause that opens a block
+// scope of its own.
+func opensScope(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.BlockStmt, *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt,
+		*ast.TypeSwitchStmt, *ast.SelectStmt, *ast.CaseClause, *ast.CommClause:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package main
+
+import "fmt"
+
+// Parameters only assigned to the blank identifier. By default _ = param
+// counts as a use, this can be changed with the -blank_assign flag.
+func blankAssigned(a int, b int) {
+	_ = a
+	fmt.Println(b)
+}
+
+func blankAssignedTogether(a, b int, c string) {
+	_, _ = a, b
+	fmt.Println(c)
+}
+
+// Also used elsewhere
+func blankAssignedAndUsed(a int) {
+	_ = a
+	fmt.Println(a)
+}
+
+// Blank assignment of an expression using the parameter
+func blankAssignedExpr(s []int) {
+	_ = len(s)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Unused parameters of function literals passed as arguments
+func literalArguments() {
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	values := []int{3, 1, 2}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[0]
+	})
+}
+
+// Unused parameter of a returned function literal
+func literalReturned() func(int) int {
+	return func(n int) int {
+		return 0
+	}
+}
+
+// Unused parameters of function literals launched with go and defer
+func literalsLaunched(done chan struct{}) {
+	go func(id int) {
+		close(done)
+	}(1)
+	defer func(err error) {
+		println()
+	}(nil)
+}
+
+// Unused parameter of a function literal nested in another
+func literalsNested() {
+	run(func(outer int) {
+		run(func(inner int) {
+			println(outer)
+		})
+	})
+}
+
+func run(f func(int)) {
+	f(0)
+}
+
+type config struct {
+	OnOpen  func(name string)
+	OnClose func(name string, err error)
+}
+
+// Unused parameters of function literals stored in composite literals
+func literalsInCompositeLiterals() {
+	cfg := config{
+		OnOpen: func(name string) {
+			println(name)
+		},
+		OnClose: func(name string, err error) {
+			println(name)
+		},
+	}
+	cfg.OnClose("", nil)
+
+	filters := []func(int) bool{
+		func(v int) bool { return v > 0 },
+		func(v int) bool { return true },
+	}
+	_ = filters
+
+	routes := map[string]map[string]func(w http.ResponseWriter){
+		"GET": {
+			"/": func(w http.ResponseWriter) {},
+		},
+	}
+	_ = routes
+}
+
+type handler struct {
+	onEvent func(e string)
+}
+
+// Unused parameters of function literals assigned to fields and map entries
+func literalsAssigned(h *handler, table map[string]func(x int), k string) {
+	h.onEvent = func(e string) {}
+	table[k] = func(x int) {}
+	handlers := []func(int){nil}
+	handlers[0] = func(n int) { println(n) }
+}
@@ -0,0 +1,67 @@
+package main
+
+import "fmt"
+
+const debug = false
+
+// Parameters only used in unreachable code. Uses in dead code are counted
+// by default, this can be changed by setting the -dead_code flag to true.
+func usedIfFalse(a int, b int) {
+	if false {
+		fmt.Println(a)
+	}
+	fmt.Println(b)
+}
+
+func usedAfterReturn(a int) int {
+	return 0
+	fmt.Println(a)
+	return a
+}
+
+func usedAfterPanic(a int) {
+	panic("unreachable")
+	fmt.Println(a)
+}
+
+func usedUnderDebug(a int, b bool) {
+	if debug && b {
+		fmt.Println(a)
+	}
+}
+
+// The else branch of a constant true condition is dead
+func usedInElse(a int) {
+	if !debug {
+		fmt.Println("live")
+	} else {
+		fmt.Println(a)
+	}
+}
+
+// Statements after a label may be reached by a goto
+func usedAfterLabel(a int, n int) {
+	if n > 0 {
+		goto done
+	}
+	return
+done:
+	fmt.Println(a)
+}
+
+// Also used in live code
+func usedInBoth(a int) {
+	if false {
+		fmt.Println(a)
+	}
+	fmt.Println(a)
+}
+
+// Dead code in a function literal
+var deadClosure = func(a, b int) {
+	for {
+		break
+		fmt.Println(a)
+	}
+	fmt.Println(b)
+}
@@ -0,0 +1,47 @@
+package main
+
+import "net/http"
+
+//nargs:ignore implements a callback signature
+func ignored(a int, b string) {
+}
+
+// Documented, then ignored.
+//
+//nolint:nargs
+func nolinted(a int) {
+}
+
+//nolint:errcheck
+func otherLinter(a int) {
+}
+
+func okParams(a /*nargs:ok*/, b int /*nargs:ok*/, c, d string) {
+}
+
+func register() {
+	//nargs:ignore
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	})
+
+	handler := func(w http.ResponseWriter, r *http.Request) { //nolint:nargs // signature
+	}
+	_ = handler
+
+	http.HandleFunc("/other", func(w http.ResponseWriter, r *http.Request) {
+	})
+}
+
+func nestedIgnored(a int) func(int) { //nargs:ignore
+	return func(n int) {}
+}
+
+func okLast(a int, r string /*nargs:ok*/) int {
+	return a
+}
+
+// Formatted by gofmt from a bare //nolint.
+//
+// nolint
+func spacedNolint(a int) {
+}
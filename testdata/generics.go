@@ -0,0 +1,90 @@
+package main
+
+type lesser[T any] interface {
+	Less(T) bool
+}
+
+type pair[K comparable, V any] struct {
+	k K
+	v V
+}
+
+// Unused type parameters. Unused type parameters are NOT flagged by default,
+// this can be enabled by setting the -type_params flag to true.
+func newPair[K any, V any]() {
+}
+
+// A type parameter referenced only by its own constraint is unused
+func selfConstrained[T lesser[T]]() {
+}
+
+// Type parameters used in the signature and in the constraint of another
+// type parameter
+func keys[M ~map[K]V, K comparable, V any](m M) []K {
+	r := make([]K, 0, len(m))
+	for k := range m {
+		r = append(r, k)
+	}
+	return r
+}
+
+// Type parameters used in instantiations and composite literal types
+func instantiated[K comparable, V any, E any](k K) int {
+	p := pair[K, V]{k: k}
+	_ = p
+	return len([]E{})
+}
+
+// Type parameters used by local types and function literals
+func local[T any, U any, W any]() {
+	type box struct {
+		v T
+	}
+	_ = box{}
+	f := func(u U) {
+		println(u)
+	}
+	_ = f
+}
+
+// Type parameter only used in conversions, but parameter unused
+func converted[T ~int](x int, unused string) T {
+	return T(x)
+}
+
+type list[T any] struct {
+	items []T
+}
+
+// Unused receiver type parameter. Unused receiver type parameters are NOT
+// flagged by default, this can be enabled by setting the
+// -receiver_type_params flag to true.
+func (l *list[T]) Len() int {
+	return len(l.items)
+}
+
+// Receiver type parameter used in the signature
+func (l *list[T]) Push(v T) {
+	l.items = append(l.items, v)
+}
+
+type dict[K comparable, V any] map[K]V
+
+// Receiver type parameters used in the body only
+func (d dict[K, V]) Clear() {
+	for k := range d {
+		var zero V
+		_ = zero
+		delete(d, K(k))
+	}
+}
+
+// One of two receiver type parameters unused, the other blank
+func (d dict[K, V]) Has(k K) bool {
+	_, ok := d[k]
+	return ok
+}
+
+func (d dict[_, V]) Any() bool {
+	return len(d) != 0
+}
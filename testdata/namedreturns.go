@@ -0,0 +1,89 @@
+package main
+
+import "errors"
+
+// Named result never assigned, always returned as the zero value
+func neverAssigned(n int) (count int, err error) {
+	if n < 0 {
+		err = errors.New("negative")
+	}
+	return
+}
+
+// Named result assigned but only returned by naked returns
+func nakedOnly(n int) (doubled int) {
+	doubled = n * 2
+	return
+}
+
+// Named result assigned, then returned explicitly
+func explicitReturn(n int) (doubled int) {
+	doubled = n * 2
+	return doubled
+}
+
+// Named result assigned, then replaced by an explicit return: not reported,
+// as assignments to named results may be seen by the caller
+func discarded(n int) (doubled int) {
+	doubled = n * 2
+	return n
+}
+
+// Named result read by a deferred closure
+func deferred() (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.New("wrapped")
+		}
+	}()
+	err = errors.New("failed")
+	return
+}
+
+func lookup(key string) (string, error) {
+	return key, nil
+}
+
+// Named result shadowed in an if block, then returned unchanged
+func shadowedResult(key string) (value string, err error) {
+	if key != "" {
+		v, err := lookup(key)
+		if err != nil {
+			return v, err
+		}
+		value = v
+	}
+	return
+}
+
+// Named result shadowed in scopes not followed by a naked return
+func shadowedThenReturned(key string) (value string, err error) {
+	for i := 0; i < 2; i++ {
+		if _, err := lookup(key); err != nil {
+			return "", err
+		}
+	}
+	value, err = lookup(key)
+	return value, err
+}
+
+// Named result assigned by a deferred closure after recovering from a panic,
+// and one not assigned although returns set it
+func recovered(key string) (n int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("recovered")
+		}
+	}()
+	return len(key), nil
+}
+
+// Named result assigned, with a naked return only in a function literal
+func nakedInLiteral() (err error) {
+	err = errors.New("failed")
+	h := func() (n int) {
+		return
+	}
+	h()
+	return nil
+}
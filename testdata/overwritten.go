@@ -0,0 +1,89 @@
+package main
+
+import "fmt"
+
+// Parameters overwritten before they are read. Overwritten parameters are
+// NOT flagged by default, this can be enabled by setting the -overwritten
+// flag to true.
+func overwritten(buf []byte, n int) {
+	buf = make([]byte, 10)
+	n, err := fmt.Println(buf)
+	fmt.Println(n, err)
+}
+
+// Overwritten on every branch, or returned before being read
+func overwrittenOnEveryPath(s string, a, b bool) string {
+	if a {
+		s = "a"
+	} else if b {
+		panic("b")
+	} else {
+		s = "b"
+	}
+	return s
+}
+
+// Read on one path
+func readOnOnePath(s string, cond bool) string {
+	if cond {
+		s = "a"
+	}
+	return s
+}
+
+// Read by the assignment overwriting it
+func readByAssignment(n int) int {
+	n = n * 2
+	return n
+}
+
+// Shadowed in an inner block rather than overwritten
+func shadowed(x int) int {
+	{
+		x := 1
+		_ = x
+	}
+	return x
+}
+
+// Read by a closure declared before the assignment
+func readByClosure(x int) int {
+	f := func() int {
+		return x
+	}
+	x = 2
+	return f()
+}
+
+// Overwritten closure parameter
+var overwrittenClosure = func(err error) error {
+	err = fmt.Errorf("wrapped")
+	return err
+}
+
+// Functions containing a goto are not analysed
+func withGoto(x int) int {
+	x = 1
+loop:
+	if x < 10 {
+		x++
+		goto loop
+	}
+	return x
+}
+
+// Read by the recursive call overwriting it
+func readByRecursiveCall(depth, n int) int {
+	if n == 0 {
+		return 0
+	}
+	depth = readByRecursiveCall(depth, n-1)
+	return depth + 1
+}
+
+// Read by an increment before being overwritten
+func readByIncrement(n int) int {
+	n += 1
+	n = 5
+	return n
+}
@@ -0,0 +1,5 @@
+package pkg
+
+func Sum(a, b, c int) int {
+	return a + b
+}
@@ -0,0 +1,22 @@
+package receivers
+
+// Some methods of counter use their receiver, others do not
+type counter struct {
+	n int
+}
+
+func (c *counter) Inc() {
+	c.n++
+}
+
+func (c *counter) Name() string {
+	return "counter"
+}
+
+func (counter) Kind() string {
+	return "int"
+}
+
+func (c counter) Unit() string {
+	return "items"
+}
@@ -0,0 +1,22 @@
+package receivers
+
+// None of the methods of formatter use their receiver, they could be
+// functions
+type formatter struct{}
+
+func (f formatter) Bold(s string) string {
+	return "*" + s + "*"
+}
+
+func (_ formatter) Italic(s string) string {
+	return "_" + s + "_"
+}
+
+// Methods of marker do not use their receiver, but do not name it either
+type marker struct{}
+
+func (marker) Mark() {}
+
+func (m *formatter) Plain(s string) string {
+	return s
+}
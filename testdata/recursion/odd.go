@@ -0,0 +1,17 @@
+package recursion
+
+func isOdd(n int, trace bool) bool {
+	if n == 0 {
+		return false
+	}
+	return isEven(n-1, trace)
+}
+
+// Passed on to a function that uses it
+func logged(msg string) {
+	println(msg)
+}
+
+func forward(msg string) {
+	logged(msg)
+}
@@ -0,0 +1,61 @@
+package recursion
+
+type node struct {
+	next  *node
+	value int
+}
+
+// Parameter only passed on to the recursive call. Recursion-only parameters
+// are NOT flagged by default, this can be enabled by setting the -recursive
+// flag to true.
+func walk(n *node, depth int) int {
+	if n == nil {
+		return 0
+	}
+	return n.value + walk(n.next, depth)
+}
+
+// Parameter used besides being passed on
+func walkUsed(n *node, depth int) int {
+	if n == nil {
+		return depth
+	}
+	return walkUsed(n.next, depth+1)
+}
+
+// Parameters swapped between positions, never used
+func swap(a, b int, n int) int {
+	if n == 0 {
+		return 0
+	}
+	return swap(b, a, n-1)
+}
+
+// Variadic parameter passed on
+func variadic(n int, rest ...string) int {
+	if n == 0 {
+		return 0
+	}
+	return variadic(n-1, rest...)
+}
+
+type tree struct {
+	children []*tree
+}
+
+// Method recursion through the receiver
+func (t *tree) size(prefix string) int {
+	total := 1
+	for _, child := range t.children {
+		total += child.size(prefix)
+	}
+	return total + t.size(prefix)
+}
+
+// Mutually recursive parameters, only reported with -mutual_recursion
+func isEven(n int, trace bool) bool {
+	if n == 0 {
+		return true
+	}
+	return isOdd(n-1, trace)
+}
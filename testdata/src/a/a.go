@@ -0,0 +1,33 @@
+package a
+
+import "fmt"
+
+func funcOne(a int, b int, c int) int { // want "funcOne contains unused parameter c"
+	return a + b
+}
+
+type f struct{}
+
+func (f) funcTwo(x int, y int, z int) int { // want "funcTwo contains unused parameter z"
+	return x + y
+}
+
+func (recv f) funcThree() int {
+	return 5
+}
+
+func funcFour() (namedReturn int) {
+	return
+}
+
+func unusedClosureParamInsideFunction() {
+	closureOne := func(v int) { // want "closureOne contains unused parameter v"
+		enclosed := 2
+		enclosed++
+	}
+	closureOne(1)
+}
+
+var closureTwo = func(i int) { // want "closureTwo contains unused parameter i"
+	fmt.Println()
+}
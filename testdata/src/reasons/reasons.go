@@ -0,0 +1,46 @@
+package reasons
+
+func unused(a int, b int) int { // want "unused contains unused parameter b"
+	return a
+}
+
+func wo(p int) { // want "wo contains write-only parameter p"
+	p = 1
+}
+
+func over(buf []byte) int { // want "over contains overwritten parameter buf"
+	buf = make([]byte, 10)
+	return len(buf)
+}
+
+func rec(n int, depth int) int { // want "rec contains recursion-only parameter depth"
+	if n == 0 {
+		return 0
+	}
+	return rec(n-1, depth)
+}
+
+func dead(x int) { // want "dead contains parameter x only used in dead code on line 25"
+	return
+	println(x)
+}
+
+func blank(x int) { // want "blank contains parameter x used only in blank assignment on line 29, rename to _ instead"
+	_ = x
+}
+
+func shadow(x int) int { // want "shadow contains parameter x shadowed before use on line 34"
+	{
+		x := 1
+		return x
+	}
+}
+
+func never() (n int) { // want "never contains named return n that is never assigned"
+	return
+}
+
+func naked() (n int) { // want "naked contains named return n only returned by naked returns"
+	n = 1
+	return
+}
@@ -0,0 +1,26 @@
+package main
+
+//nargs:ignore the parameter is used now
+func nowUsed(a int) int {
+	return a
+}
+
+//nargs:ignore until=2020-01-01
+func expiredIgnore(a int) {
+}
+
+//nargs:ignore until=2999-01-01
+func futureIgnore(a int) {
+}
+
+func okUsed(a /*nargs:ok*/ int) int {
+	return a
+}
+
+// nolint
+func bareNolint(a int) int {
+	return a
+}
+
+//nargs:ignore
+var notAFunction = 1
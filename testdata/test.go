@@ -53,11 +53,11 @@ func variableCapturedByClosure(r int) {
 	feedTokens(5)
 }
 
-// TODO - functions as keys
-// var usedAsGlobalInterfaceMapValue = map[string]interface{}{
-// 	"someFunc": func(i int, s string) {
-// 		if i == 0 {
-// 			println()
-// 		}
-// 	},
-// }
+// Unused closure parameter in a package scoped map value
+var usedAsGlobalInterfaceMapValue = map[string]interface{}{
+	"someFunc": func(i int, s string) {
+		if i == 0 {
+			println()
+		}
+	},
+}
@@ -0,0 +1,37 @@
+package main
+
+import "fmt"
+
+// The closure parameter x shadows the parameter x of shadowedByClosure,
+// which is reported as shadowed before use with or without type information.
+func shadowedByClosure(x int) {
+	double := func(x int) int {
+		return x * 2
+	}
+	fmt.Println(double(1))
+}
+
+// The local variable n shadows the parameter n.
+func shadowedByLocal(n int) {
+	{
+		n := 2
+		fmt.Println(n)
+	}
+}
+
+// m is used through the closure
+func capturedByClosure(m int) {
+	print := func() {
+		fmt.Println(m)
+	}
+	print()
+}
+
+// The local variable err shadows the parameter err only after it is used
+func usedThenShadowed(err error) {
+	fmt.Println(err)
+	{
+		err := fmt.Errorf("inner")
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+// Parameters only ever assigned to. Write-only parameters are NOT flagged by
+// default, this can be enabled by setting the -write_only flag to true.
+func reassigned(n int, m int, total int) {
+	n = 1
+	m++
+	total += 2
+}
+
+// Assigning through a parameter or taking its address reads it
+func assignedThrough(p *int, q []int, s struct{ f int }, n int) *int {
+	*p = 1
+	q[0] = 2
+	s.f = 3
+	n = 4
+	return &n
+}
+
+// Range variables assigned to
+func ranged(k int, v string, xs []string) {
+	for k, v = range xs {
+	}
+}
+
+// Named results are assigned to be returned, reported with -named_returns
+func nakedReturn() (err error, unset int) {
+	err = nil
+	return
+}
+
+// Write-only closure parameter
+func writeOnlyClosure(r int) {
+	f := func(n int) {
+		n = r
+	}
+	f(1)
+}
+
+// Write-only parameter, the closure reads its own parameter of the same name
+func writeOnlyShadowedByClosure(x int) int {
+	x = 2
+	double := func(x int) int {
+		return x * 2
+	}
+	return double(3)
+}